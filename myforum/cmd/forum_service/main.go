@@ -2,12 +2,19 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/jaxxiy/myforum/internal/app"
+	"github.com/jaxxiy/myforum/internal/config"
 )
 
 func main() {
-	srv := app.NewServer()
+	cfg, err := config.Load("forum_service", os.Args[1:])
+	if err != nil {
+		log.Fatalf("Ошибка конфигурации: %v", err)
+	}
+
+	srv := app.NewServer(cfg)
 	if err := srv.Run(); err != nil {
 		log.Fatalf("Ошибка запуска: %v", err)
 	}
@@ -0,0 +1,81 @@
+// Продление сессии: access-токен живет недолго, поэтому незадолго до его
+// истечения страница обменивает refresh-токен на новую пару токенов.
+(function () {
+    const AUTH_URL = 'http://localhost:3000/auth';
+    const REFRESH_MARGIN_MS = 60 * 1000;
+
+    function tokenExpiry(token) {
+        try {
+            const payload = token.split('.')[1].replace(/-/g, '+').replace(/_/g, '/');
+            return JSON.parse(atob(payload)).exp * 1000;
+        } catch (e) {
+            return 0;
+        }
+    }
+
+    function save(data) {
+        localStorage.setItem('jwt', data.token);
+        localStorage.setItem('refresh_token', data.refresh_token);
+        localStorage.setItem('username', data.user.username);
+        localStorage.setItem('user_id', data.user.id);
+    }
+
+    function clear() {
+        ['jwt', 'refresh_token', 'username', 'user_id'].forEach(key => localStorage.removeItem(key));
+    }
+
+    let timer;
+    function schedule() {
+        clearTimeout(timer);
+        const token = localStorage.getItem('jwt');
+        if (!token || !localStorage.getItem('refresh_token')) return;
+        timer = setTimeout(refresh, Math.max(tokenExpiry(token) - Date.now() - REFRESH_MARGIN_MS, 0));
+    }
+
+    async function refresh() {
+        const refreshToken = localStorage.getItem('refresh_token');
+        if (!refreshToken) return false;
+        let response;
+        try {
+            response = await fetch(`${AUTH_URL}/refresh`, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ refresh_token: refreshToken })
+            });
+        } catch (error) {
+            // Сервис авторизации недоступен: сессию не трогаем
+            console.error('Token refresh failed:', error);
+            return false;
+        }
+        if (!response.ok) {
+            clear();
+            return false;
+        }
+        save(await response.json());
+        schedule();
+        return true;
+    }
+
+    // logout(true) завершает сессии на всех устройствах
+    async function logout(everywhere) {
+        const token = localStorage.getItem('jwt');
+        try {
+            await fetch(`${AUTH_URL}/${everywhere ? 'logout-all' : 'logout'}`, {
+                method: 'POST',
+                headers: {
+                    'Content-Type': 'application/json',
+                    ...(token ? { 'Authorization': `Bearer ${token}` } : {})
+                },
+                body: JSON.stringify({ refresh_token: localStorage.getItem('refresh_token') || '' })
+            });
+        } catch (error) {
+            console.error('Logout failed:', error);
+        } finally {
+            clear();
+            window.location.href = '/auth/login';
+        }
+    }
+
+    window.session = { save, refresh, logout };
+    schedule();
+})();
@@ -8,7 +8,8 @@ import (
 	"github.com/jaxxiy/myforum/pkg/jwt"
 )
 
-func AuthMiddleware(secret string) func(http.Handler) http.Handler {
+// AuthMiddleware пропускает запросы только с действующим, не отозванным JWT
+func AuthMiddleware(tokens *jwt.Manager, revocations jwt.RevocationChecker) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -18,7 +19,7 @@ func AuthMiddleware(secret string) func(http.Handler) http.Handler {
 			}
 
 			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-			claims, err := jwt.ParseToken(tokenString, secret)
+			claims, err := tokens.ParseActive(tokenString, revocations)
 			if err != nil {
 				http.Error(w, "Invalid token", http.StatusUnauthorized)
 				return
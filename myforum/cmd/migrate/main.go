@@ -4,20 +4,22 @@ import (
 	"database/sql"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jaxxiy/myforum/internal/config"
 	_ "github.com/lib/pq"
 )
 
 func main() {
-	dsn := os.Getenv("DB_DSN")
-	if dsn == "" {
-		log.Fatal("Переменная окружения DB_DSN не установлена")
+	cfg, err := config.Load("migrate", os.Args[1:])
+	if err != nil {
+		log.Fatalf("Ошибка конфигурации: %v", err)
 	}
 
-	db, err := sql.Open("postgres", dsn)
+	db, err := sql.Open("postgres", cfg.Database.DSN)
 	if err != nil {
 		log.Fatalf("Ошибка подключения к базе: %v", err)
 	}
@@ -28,7 +30,7 @@ func main() {
 	}
 
 	m, err := migrate.NewWithDatabaseInstance(
-		"file://../../migrations", // путь к миграциям
+		"file://"+filepath.ToSlash(cfg.Paths.Migrations),
 		"postgres",
 		driver,
 	)
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/config"
+	"github.com/jaxxiy/myforum/internal/repository"
+)
+
+// Окончательно удаляет из корзины форумы и сообщения старше trash.retention,
+// заодно удаляет истекшие refresh-токены, записи об отозванных токенах
+// и, при login.store: postgres, устаревшие счетчики неудачных входов.
+// Без trash.purge_interval очищает один раз и выходит, что удобно для cron;
+// с ним работает как сервис и очищает по расписанию.
+func main() {
+	cfg, err := config.Load("purge", os.Args[1:])
+	if err != nil {
+		log.Fatalf("Ошибка конфигурации: %v", err)
+	}
+
+	db, err := repository.NewPostgres(cfg.Database.DSN)
+	if err != nil {
+		log.Fatalf("Не удалось подключиться к базе данных: %v", err)
+	}
+	defer db.DB.Close()
+
+	trash := repository.NewForumsRepo(db.DB)
+	tokens := repository.NewTokenRepo(db.DB)
+	var logins repository.LoginAttemptStore
+	if cfg.Login.Store == "postgres" {
+		logins = repository.NewLoginAttemptRepo(db.DB)
+	}
+
+	if cfg.Trash.PurgeInterval == 0 {
+		if err := purge(trash, tokens, logins, cfg.Trash.Retention); err != nil {
+			log.Fatalf("Ошибка очистки корзины: %v", err)
+		}
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(cfg.Trash.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		if err := purge(trash, tokens, logins, cfg.Trash.Retention); err != nil {
+			log.Printf("Ошибка очистки корзины: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purge(trash repository.TrashStore, tokens repository.TokenStore, logins repository.LoginAttemptStore, retention time.Duration) error {
+	stats, err := trash.Purge(retention)
+	if err != nil {
+		return err
+	}
+	log.Printf("Корзина очищена: форумов %d, сообщений %d (старше %s)", stats.Forums, stats.Messages, retention)
+
+	expired, err := tokens.DeleteExpiredTokens(time.Now())
+	if err != nil {
+		return err
+	}
+	log.Printf("Удалено истекших токенов: %d", expired)
+
+	if logins == nil {
+		return nil
+	}
+	attempts, err := logins.DeleteExpiredLoginAttempts(time.Now())
+	if err != nil {
+		return err
+	}
+	log.Printf("Удалено устаревших счетчиков входа: %d", attempts)
+	return nil
+}
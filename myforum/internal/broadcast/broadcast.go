@@ -0,0 +1,56 @@
+// Package broadcast рассылает события WebSocket-комнат между экземплярами
+// сервера форума, чтобы их можно было запускать за балансировщиком.
+package broadcast
+
+import "sync"
+
+// Handler получает событие комнаты; вызывается на каждом экземпляре
+type Handler func(room string, data []byte)
+
+type Broadcaster interface {
+	// Publish доставляет данные подписчикам всех экземпляров, включая текущий
+	Publish(room string, data []byte) error
+	// Subscribe добавляет обработчик входящих событий
+	Subscribe(h Handler)
+	Close() error
+}
+
+// handlers — общий для реализаций список подписчиков
+type handlers struct {
+	mu   sync.RWMutex
+	list []Handler
+}
+
+func (hs *handlers) Subscribe(h Handler) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.list = append(hs.list, h)
+}
+
+func (hs *handlers) dispatch(room string, data []byte) {
+	hs.mu.RLock()
+	defer hs.mu.RUnlock()
+	for _, h := range hs.list {
+		h(room, data)
+	}
+}
+
+// Local доставляет события только внутри процесса: для одного экземпляра и тестов
+type Local struct {
+	handlers
+}
+
+var _ Broadcaster = (*Local)(nil)
+
+func NewLocal() *Local {
+	return &Local{}
+}
+
+func (l *Local) Publish(room string, data []byte) error {
+	l.dispatch(room, data)
+	return nil
+}
+
+func (l *Local) Close() error {
+	return nil
+}
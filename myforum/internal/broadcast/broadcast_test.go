@@ -0,0 +1,52 @@
+package broadcast
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type received struct {
+	room string
+	data string
+}
+
+func collect(b Broadcaster) *[]received {
+	var got []received
+	b.Subscribe(func(room string, data []byte) {
+		got = append(got, received{room, string(data)})
+	})
+	return &got
+}
+
+func TestLocalDeliversToAllSubscribers(t *testing.T) {
+	b := NewLocal()
+	first, second := collect(b), collect(b)
+
+	if err := b.Publish("forum:1", []byte(`{"type":"message_created"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := received{"forum:1", `{"type":"message_created"}`}
+	for _, got := range []*[]received{first, second} {
+		if len(*got) != 1 || (*got)[0] != want {
+			t.Fatalf("got %v, want [%v]", *got, want)
+		}
+	}
+}
+
+func TestPostgresHandleInlineEnvelope(t *testing.T) {
+	p := &Postgres{}
+	got := collect(p)
+
+	payload, _ := json.Marshal(envelope{Room: "global", Data: `{"text":"привет"}`})
+	if err := p.handle(string(payload)); err != nil {
+		t.Fatal(err)
+	}
+	if len(*got) != 1 || (*got)[0] != (received{"global", `{"text":"привет"}`}) {
+		t.Fatalf("got %v", *got)
+	}
+
+	if err := p.handle("not json"); err == nil {
+		t.Fatal("expected error for malformed payload")
+	}
+}
@@ -0,0 +1,151 @@
+package broadcast
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// Канал LISTEN/NOTIFY, общий для всех экземпляров
+	notifyChannel = "myforum_broadcast"
+	// NOTIFY принимает не больше 8000 байт; более длинные события
+	// кладутся в таблицу broadcast_events, а в уведомлении идет только ID
+	maxNotifyPayload = 7900
+	// Интервал проверки соединения слушателя
+	pingInterval = 90 * time.Second
+)
+
+// envelope — содержимое уведомления
+type envelope struct {
+	Room string `json:"room,omitempty"`
+	Data string `json:"data,omitempty"`
+	ID   int64  `json:"id,omitempty"` // событие в broadcast_events
+}
+
+// Postgres рассылает события через LISTEN/NOTIFY той же базы, что и форум.
+// Свои уведомления экземпляр тоже получает, так что локальная доставка
+// идет тем же путем, что и на другие экземпляры.
+type Postgres struct {
+	handlers
+
+	db       *sql.DB
+	listener *pq.Listener
+	done     chan struct{}
+}
+
+var _ Broadcaster = (*Postgres)(nil)
+
+// NewPostgres подписывается на канал отдельным соединением по dsn
+func NewPostgres(db *sql.DB, dsn string) (*Postgres, error) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Broadcast listener: %v", err)
+		}
+	})
+	if err := listener.Listen(notifyChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("listen %s: %w", notifyChannel, err)
+	}
+
+	p := &Postgres{
+		db:       db,
+		listener: listener,
+		done:     make(chan struct{}),
+	}
+	go p.run()
+	return p, nil
+}
+
+func (p *Postgres) Publish(room string, data []byte) error {
+	payload, err := json.Marshal(envelope{Room: room, Data: string(data)})
+	if err != nil {
+		return err
+	}
+
+	if len(payload) > maxNotifyPayload {
+		payload, err = p.store(room, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := p.db.Exec(`SELECT pg_notify($1, $2)`, notifyChannel, string(payload)); err != nil {
+		return fmt.Errorf("notify failed: %w", err)
+	}
+	return nil
+}
+
+// store сохраняет длинное событие и возвращает уведомление со ссылкой на него
+func (p *Postgres) store(room string, data []byte) ([]byte, error) {
+	var id int64
+	err := p.db.QueryRow(`
+		INSERT INTO broadcast_events (room, data)
+		VALUES ($1, $2)
+		RETURNING id`, room, string(data)).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("store broadcast event failed: %w", err)
+	}
+
+	// Получатели читают событие сразу, так что минутные события уже не нужны
+	if _, err := p.db.Exec(`DELETE FROM broadcast_events WHERE created_at < NOW() - INTERVAL '1 minute'`); err != nil {
+		log.Printf("Broadcast cleanup failed: %v", err)
+	}
+	return json.Marshal(envelope{ID: id})
+}
+
+func (p *Postgres) run() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case n, ok := <-p.listener.Notify:
+			if !ok {
+				return
+			}
+			// nil приходит после переподключения: события за время разрыва потеряны
+			if n == nil {
+				log.Println("Broadcast listener reconnected, some events may have been lost")
+				continue
+			}
+			if err := p.handle(n.Extra); err != nil {
+				log.Printf("Broadcast event dropped: %v", err)
+			}
+		case <-ticker.C:
+			go p.listener.Ping()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+func (p *Postgres) handle(payload string) error {
+	var e envelope
+	if err := json.Unmarshal([]byte(payload), &e); err != nil {
+		return err
+	}
+
+	if e.ID != 0 {
+		err := p.db.QueryRow(`SELECT room, data FROM broadcast_events WHERE id = $1`, e.ID).Scan(&e.Room, &e.Data)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("broadcast event %d not found", e.ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	p.dispatch(e.Room, []byte(e.Data))
+	return nil
+}
+
+func (p *Postgres) Close() error {
+	close(p.done)
+	return p.listener.Close()
+}
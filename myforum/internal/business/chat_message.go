@@ -9,7 +9,15 @@ type IncomingChatMessage struct {
 
 type GlobalMessage struct {
 	ID        int       `json:"id"`
+	AuthorID  int       `json:"author_id,omitempty"` // 0, если автор удален
 	Author    string    `json:"author"`
 	Content   string    `json:"content"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+type GlobalChatMessage struct {
+	ID        int       `json:"id"`
+	Author    string    `json:"author"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -2,9 +2,33 @@ package business
 
 import "time"
 
+// Видимость форума
+const (
+	VisibilityPublic   = "public"   // читают все
+	VisibilityMembers  = "members"  // читают только вошедшие пользователи
+	VisibilityArchived = "archived" // читают все, писать не может никто
+)
+
 type Forum struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	OwnerID     int        `json:"owner_id,omitempty"` // 0 — у форума нет владельца
+	Visibility  string     `json:"visibility"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"` // задано только у форумов в корзине
+	DeletedBy   int        `json:"deleted_by,omitempty"`
+}
+
+// ValidVisibility сообщает, что v — одна из видимостей форума
+func ValidVisibility(v string) bool {
+	return v == VisibilityPublic || v == VisibilityMembers || v == VisibilityArchived
+}
+
+// ForumModerator — пользователь, назначенный модератором одного форума
+type ForumModerator struct {
+	ForumID  int       `json:"forum_id"`
+	UserID   int       `json:"user_id"`
+	Username string    `json:"username"`
+	AddedAt  time.Time `json:"added_at"`
 }
@@ -0,0 +1,24 @@
+package business
+
+import "time"
+
+// По чему считаются неудачные входы
+const (
+	LoginScopeUsername = "username"
+	LoginScopeIP       = "ip"
+)
+
+// LoginAttempts — неудачные входы подряд под одним именем или с одного IP
+type LoginAttempts struct {
+	Scope         string
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time // nil — вход не заблокирован
+}
+
+// UnlockLoginRequest — что разблокировать: имя пользователя, IP или оба
+type UnlockLoginRequest struct {
+	Username string `json:"username"`
+	IP       string `json:"ip"`
+}
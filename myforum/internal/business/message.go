@@ -3,9 +3,71 @@ package business
 import "time"
 
 type Message struct {
+	ID        int           `json:"id"`
+	ForumID   int           `json:"forum_id"`
+	TopicID   int           `json:"topic_id"`
+	ParentID  int           `json:"parent_id,omitempty"`
+	AuthorID  int           `json:"author_id,omitempty"` // 0, если автор удален
+	Author    string        `json:"author"`
+	Content   string        `json:"content"`
+	Quote     *MessageQuote `json:"quote,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	EditedAt  *time.Time    `json:"edited_at,omitempty"`  // nil, если сообщение не правили
+	DeletedAt *time.Time    `json:"deleted_at,omitempty"` // задано только у сообщений в корзине
+	DeletedBy int           `json:"deleted_by,omitempty"`
+}
+
+// MessageRevision — текст сообщения до очередной правки
+type MessageRevision struct {
 	ID        int       `json:"id"`
-	ForumID   int       `json:"forum_id"`
-	Author    string    `json:"author"`
+	MessageID int       `json:"message_id"`
 	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
+	EditorID  int       `json:"editor_id"` // 0, если редактор удален
+	Editor    string    `json:"editor,omitempty"`
+	EditedAt  time.Time `json:"edited_at"`
+}
+
+// MessageQuote — снимок цитируемого сообщения на момент цитирования.
+// Он не меняется, если исходное сообщение потом отредактируют или удалят.
+type MessageQuote struct {
+	MessageID int    `json:"message_id"`
+	Author    string `json:"author"`
+	Content   string `json:"content"`
+}
+
+// MessageNode — сообщение с ответами на него
+type MessageNode struct {
+	Message
+	Depth   int            `json:"depth"`
+	Replies []*MessageNode `json:"replies"`
+}
+
+// BuildMessageTree раскладывает сообщения в дерево ответов. Сообщения должны
+// идти по возрастанию created_at, тогда родитель всегда встречается раньше ответа.
+// Ответы глубже maxDepth прикрепляются к предку на уровне maxDepth, поэтому
+// ни одно сообщение не теряется. Ответ на отсутствующее в списке сообщение
+// становится корневым.
+func BuildMessageTree(messages []Message, maxDepth int) []*MessageNode {
+	if maxDepth < 1 {
+		maxDepth = 1
+	}
+
+	nodes := make(map[int]*MessageNode, len(messages))
+	roots := []*MessageNode{}
+	for _, m := range messages {
+		n := &MessageNode{Message: m, Replies: []*MessageNode{}}
+
+		parent, ok := nodes[m.ParentID]
+		if m.ParentID == 0 || !ok {
+			roots = append(roots, n)
+		} else {
+			for parent.Depth >= maxDepth {
+				parent = nodes[parent.ParentID]
+			}
+			n.Depth = parent.Depth + 1
+			parent.Replies = append(parent.Replies, n)
+		}
+		nodes[m.ID] = n
+	}
+	return roots
 }
@@ -0,0 +1,28 @@
+package business
+
+import "time"
+
+// UserProfile — публичный профиль пользователя. Email в него не входит.
+type UserProfile struct {
+	ID        int       `json:"id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	Bio       string    `json:"bio"`
+	AvatarURL string    `json:"avatar_url"`
+	JoinedAt  time.Time `json:"joined_at"`
+	UserActivity
+}
+
+// UserActivity — сообщения пользователя в форумах, которые видит смотрящий
+type UserActivity struct {
+	MessageCount int             `json:"message_count"`
+	Forums       []ForumActivity `json:"forums"`
+}
+
+// ForumActivity — участие пользователя в одном форуме
+type ForumActivity struct {
+	ForumID      int       `json:"forum_id"`
+	Title        string    `json:"title"`
+	MessageCount int       `json:"message_count"`
+	LastPostAt   time.Time `json:"last_post_at"`
+}
@@ -0,0 +1,20 @@
+package business
+
+import "time"
+
+// Типы результатов поиска
+const (
+	SearchResultForum   = "forum"
+	SearchResultMessage = "message"
+)
+
+type SearchResult struct {
+	Type       string    `json:"type"`
+	ID         int       `json:"id"`
+	ForumID    int       `json:"forum_id"`
+	ForumTitle string    `json:"forum_title"`
+	Author     string    `json:"author,omitempty"`
+	Snippet    string    `json:"snippet"` // HTML: текст экранирован, совпадения в <mark>
+	Rank       float64   `json:"rank"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,51 @@
+package business
+
+import "time"
+
+// RefreshToken — долгоживущий токен сессии. В базе хранится только SHA-256
+// от токена, сам токен знает лишь клиент.
+type RefreshToken struct {
+	ID        int
+	UserID    int
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt *time.Time // nil, пока токен действует
+	// ID токена, на который этот обменяли; 0 — токен отозван выходом
+	ReplacedBy int
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Назначение одноразового токена из письма
+const (
+	PurposePasswordReset     = "password_reset"
+	PurposeEmailVerification = "email_verification"
+)
+
+// OneTimeToken — токен из ссылки в письме: сброс пароля или подтверждение
+// адреса. Как и у refresh-токена, в базе хранится только SHA-256.
+type OneTimeToken struct {
+	ID        int
+	UserID    int
+	Purpose   string
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time // nil, пока токен не использован
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
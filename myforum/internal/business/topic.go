@@ -1,8 +1,12 @@
 package business
 
+import "time"
+
 type Topic struct {
-	ID      int    `json:"id"`
-	ForumID int    `json:"forum_id"`
-	Title   string `json:"title"`
-	Desc    string `json:"description"`
+	ID        int       `json:"id"`
+	ForumID   int       `json:"forum_id"`
+	Title     string    `json:"title"`
+	Desc      string    `json:"description"`
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
 }
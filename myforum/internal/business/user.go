@@ -12,6 +12,34 @@ type User struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	Role      string    `json:"role"`
+	// Когда пользователь подтвердил email; nil — не подтвердил
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	Bio             string     `json:"bio"`
+	AvatarURL       string     `json:"avatar_url"`
+}
+
+// DeletedAuthor подставляется автором сообщений удаленного аккаунта
+const DeletedAuthor = "[deleted]"
+
+// ProfileUpdate — поля профиля, которые меняет сам пользователь; nil — не менять
+type ProfileUpdate struct {
+	Bio       *string
+	AvatarURL *string
+}
+
+// UpdateAccountRequest — тело PATCH /api/me. Для смены email или пароля
+// нужен текущий пароль.
+type UpdateAccountRequest struct {
+	Bio             *string `json:"bio"`
+	AvatarURL       *string `json:"avatar_url"`
+	Email           *string `json:"email"`
+	NewPassword     *string `json:"new_password"`
+	CurrentPassword string  `json:"current_password"`
+}
+
+// DeleteAccountRequest — тело DELETE /api/me
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
 }
 
 type LoginRequest struct {
@@ -26,6 +54,8 @@ type RegisterRequest struct {
 }
 
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // срок жизни token в секундах
+	User         User   `json:"user"`
 }
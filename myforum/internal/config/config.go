@@ -0,0 +1,535 @@
+// Package config загружает настройки сервисов форума.
+//
+// Значения применяются в порядке возрастания приоритета: значения по
+// умолчанию, YAML-файл, переменные окружения, флаги командной строки.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile читается, если путь к файлу не задан явно и файл существует
+const DefaultFile = "config.yaml"
+
+type Config struct {
+	Database  DatabaseConfig  `yaml:"database"`
+	HTTP      HTTPConfig      `yaml:"http"`
+	GRPC      GRPCConfig      `yaml:"grpc"`
+	WebSocket WebSocketConfig `yaml:"websocket"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Mail      MailConfig      `yaml:"mail"`
+	Login     LoginConfig     `yaml:"login"`
+	Trash     TrashConfig     `yaml:"trash"`
+	Paths     PathsConfig     `yaml:"paths"`
+}
+
+type DatabaseConfig struct {
+	DSN string `yaml:"dsn"`
+}
+
+type HTTPConfig struct {
+	Addr string `yaml:"addr"`
+}
+
+type GRPCConfig struct {
+	Addr string `yaml:"addr"`
+}
+
+// WebSocketConfig — доступ к /ws/global и /ws/{forum_id}
+type WebSocketConfig struct {
+	// Без токена соединение получает события, но не может писать в чат
+	AllowAnonymous bool `yaml:"allow_anonymous"`
+	// Как события доходят до соединений других экземпляров сервера:
+	// local — только свои соединения, postgres — через LISTEN/NOTIFY
+	Broadcast string `yaml:"broadcast"`
+}
+
+// AuthConfig — ключи JWT и настройки отдельного сервиса авторизации
+type AuthConfig struct {
+	// Общий секрет HS256; не нужен, если заданы асимметричные ключи
+	JWTSecret string `yaml:"jwt_secret"`
+	// Закрытый ключ RSA или Ed25519 (PEM), которым сервис авторизации подписывает токены
+	SigningKeyFile string `yaml:"signing_key_file"`
+	// Открытые ключи (PEM), которые еще принимаются: прежний ключ при смене
+	VerificationKeyFiles []string `yaml:"verification_key_files"`
+	// JWKS сервиса авторизации, по которому форум проверяет токены, и срок его кеша
+	JWKSURL     string        `yaml:"jwks_url"`
+	JWKSRefresh time.Duration `yaml:"jwks_refresh"`
+	// Издатель (iss) и получатель (aud) токенов; токены с другими значениями отклоняются
+	Issuer        string `yaml:"issuer"`
+	Audience      string `yaml:"audience"`
+	Addr          string `yaml:"addr"`
+	AllowedOrigin string `yaml:"allowed_origin"`
+	// Срок жизни access-токена; продлевается обменом refresh-токена
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+	// Адрес сервиса авторизации, из которого собираются ссылки в письмах
+	PublicURL string `yaml:"public_url"`
+	// Сколько действуют ссылки сброса пароля и подтверждения email
+	PasswordResetTTL     time.Duration `yaml:"password_reset_ttl"`
+	EmailVerificationTTL time.Duration `yaml:"email_verification_ttl"`
+}
+
+// MailConfig — отправка писем сервисом авторизации и форумом (после смены email)
+type MailConfig struct {
+	// smtp — через SMTP-сервер; file — письма складываются в Dir как .eml
+	Driver string `yaml:"driver"`
+	From   string `yaml:"from"`
+	Dir    string `yaml:"dir"`
+	// Адрес host:port и учетная запись SMTP-сервера
+	SMTPAddr     string `yaml:"smtp_addr"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+}
+
+// LoginConfig — защита входа и проверки пароля в /api/me от перебора паролей.
+// Неудачные попытки считаются отдельно по имени пользователя и по IP.
+type LoginConfig struct {
+	// Где хранятся счетчики: memory — в процессе, postgres — общие для всех экземпляров
+	Store string `yaml:"store"`
+	// Сколько неудач подряд блокируют вход на LockoutDuration; 0 — не блокировать
+	MaxFailures      int `yaml:"max_failures"`
+	MaxFailuresPerIP int `yaml:"max_failures_per_ip"`
+	// Неудачи старше Window забываются
+	Window time.Duration `yaml:"window"`
+	// Пауза перед следующей попыткой после первой неудачи; после каждой
+	// следующей удваивается. 0 — без пауз
+	Delay           time.Duration `yaml:"delay"`
+	LockoutDuration time.Duration `yaml:"lockout_duration"`
+	// Брать IP клиента из X-Forwarded-For; только за доверенным прокси
+	TrustProxy bool `yaml:"trust_proxy"`
+}
+
+// TrashConfig — срок хранения мягко удаленных форумов и сообщений
+type TrashConfig struct {
+	Retention time.Duration `yaml:"retention"`
+	// Период очистки в cmd/purge; 0 — очистить один раз и выйти (для cron)
+	PurgeInterval time.Duration `yaml:"purge_interval"`
+}
+
+// PathsConfig — пути к файлам, относительные пути считаются от рабочего каталога
+type PathsConfig struct {
+	Templates  string `yaml:"templates"`
+	Static     string `yaml:"static"`
+	Migrations string `yaml:"migrations"`
+}
+
+func Default() *Config {
+	return &Config{
+		HTTP: HTTPConfig{Addr: ":8080"},
+		GRPC: GRPCConfig{Addr: ":9090"},
+		WebSocket: WebSocketConfig{
+			Broadcast: "local",
+		},
+		Auth: AuthConfig{
+			JWKSRefresh:     5 * time.Minute,
+			Issuer:          "myforum-auth",
+			Audience:        "myforum",
+			Addr:            ":3000",
+			AllowedOrigin:   "http://localhost:8080",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+
+			PublicURL:            "http://localhost:3000",
+			PasswordResetTTL:     time.Hour,
+			EmailVerificationTTL: 48 * time.Hour,
+		},
+		Mail: MailConfig{
+			Driver: "file",
+			From:   "myforum@localhost",
+			Dir:    "mail",
+		},
+		Login: LoginConfig{
+			Store:            "memory",
+			MaxFailures:      5,
+			MaxFailuresPerIP: 50,
+			Window:           15 * time.Minute,
+			Delay:            time.Second,
+			LockoutDuration:  15 * time.Minute,
+		},
+		Trash: TrashConfig{Retention: 30 * 24 * time.Hour},
+		Paths: PathsConfig{
+			Templates:  "templates/*.html",
+			Static:     "cmd/frontend",
+			Migrations: "migrations",
+		},
+	}
+}
+
+// setting связывает поле конфигурации с переменными окружения и флагом
+type setting struct {
+	field value
+	flag  string
+	usage string
+	env   []string // по убыванию приоритета, устаревшие имена последними
+}
+
+// value — поле конфигурации, которое задается строкой из окружения или флага
+type value interface {
+	Set(v string) error
+}
+
+type stringField struct{ p *string }
+
+func (f stringField) Set(v string) error {
+	*f.p = v
+	return nil
+}
+
+type boolField struct{ p *bool }
+
+func (f boolField) Set(v string) error {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return err
+	}
+	*f.p = b
+	return nil
+}
+
+// stringListField — список через запятую
+type stringListField struct{ p *[]string }
+
+func (f stringListField) Set(v string) error {
+	var list []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	*f.p = list
+	return nil
+}
+
+type intField struct{ p *int }
+
+func (f intField) Set(v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return err
+	}
+	*f.p = n
+	return nil
+}
+
+type durationField struct{ p *time.Duration }
+
+func (f durationField) Set(v string) error {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return err
+	}
+	*f.p = d
+	return nil
+}
+
+// rawFlag запоминает значение флага, чтобы применить его после файла и окружения
+type rawFlag struct {
+	value  string
+	isBool bool
+}
+
+func (f *rawFlag) String() string     { return f.value }
+func (f *rawFlag) Set(v string) error { f.value = v; return nil }
+func (f *rawFlag) IsBoolFlag() bool   { return f.isBool }
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{stringField{&c.Database.DSN}, "db-dsn", "строка подключения к Postgres", []string{"MYFORUM_DB_DSN", "DB_DSN", "DATABASE_URL"}},
+		{stringField{&c.HTTP.Addr}, "http-addr", "адрес HTTP-сервера", []string{"MYFORUM_HTTP_ADDR"}},
+		{stringField{&c.GRPC.Addr}, "grpc-addr", "адрес gRPC-сервера", []string{"MYFORUM_GRPC_ADDR"}},
+		{boolField{&c.WebSocket.AllowAnonymous}, "ws-allow-anonymous", "разрешить WebSocket без токена в режиме только для чтения", []string{"MYFORUM_WS_ALLOW_ANONYMOUS"}},
+		{stringField{&c.WebSocket.Broadcast}, "ws-broadcast", "рассылка событий между экземплярами: local или postgres", []string{"MYFORUM_WS_BROADCAST"}},
+		{stringField{&c.Auth.JWTSecret}, "jwt-secret", "секрет подписи JWT", []string{"MYFORUM_JWT_SECRET", "JWT_SECRET"}},
+		{stringField{&c.Auth.SigningKeyFile}, "jwt-signing-key", "PEM-файл закрытого ключа RSA или Ed25519 для подписи JWT", []string{"MYFORUM_JWT_SIGNING_KEY"}},
+		{stringListField{&c.Auth.VerificationKeyFiles}, "jwt-verification-keys", "PEM-файлы открытых ключей проверки JWT через запятую", []string{"MYFORUM_JWT_VERIFICATION_KEYS"}},
+		{stringField{&c.Auth.JWKSURL}, "jwks-url", "адрес JWKS сервиса авторизации для проверки JWT", []string{"MYFORUM_JWKS_URL"}},
+		{durationField{&c.Auth.JWKSRefresh}, "jwks-refresh", "сколько кешировать JWKS, например 5m", []string{"MYFORUM_JWKS_REFRESH"}},
+		{stringField{&c.Auth.Issuer}, "jwt-issuer", "издатель JWT (iss)", []string{"MYFORUM_JWT_ISSUER"}},
+		{stringField{&c.Auth.Audience}, "jwt-audience", "получатель JWT (aud)", []string{"MYFORUM_JWT_AUDIENCE"}},
+		{stringField{&c.Auth.Addr}, "auth-addr", "адрес сервиса авторизации", []string{"MYFORUM_AUTH_ADDR"}},
+		{stringField{&c.Auth.AllowedOrigin}, "auth-allowed-origin", "origin, которому сервис авторизации разрешает CORS", []string{"MYFORUM_AUTH_ALLOWED_ORIGIN"}},
+		{durationField{&c.Auth.AccessTokenTTL}, "access-token-ttl", "срок жизни access-токена, например 15m", []string{"MYFORUM_ACCESS_TOKEN_TTL"}},
+		{durationField{&c.Auth.RefreshTokenTTL}, "refresh-token-ttl", "срок жизни refresh-токена, например 720h", []string{"MYFORUM_REFRESH_TOKEN_TTL"}},
+		{stringField{&c.Auth.PublicURL}, "auth-public-url", "адрес сервиса авторизации для ссылок в письмах", []string{"MYFORUM_AUTH_PUBLIC_URL"}},
+		{durationField{&c.Auth.PasswordResetTTL}, "password-reset-ttl", "срок действия ссылки сброса пароля, например 1h", []string{"MYFORUM_PASSWORD_RESET_TTL"}},
+		{durationField{&c.Auth.EmailVerificationTTL}, "email-verification-ttl", "срок действия ссылки подтверждения email, например 48h", []string{"MYFORUM_EMAIL_VERIFICATION_TTL"}},
+		{stringField{&c.Mail.Driver}, "mail-driver", "отправка писем: smtp или file", []string{"MYFORUM_MAIL_DRIVER"}},
+		{stringField{&c.Mail.From}, "mail-from", "адрес отправителя писем", []string{"MYFORUM_MAIL_FROM"}},
+		{stringField{&c.Mail.Dir}, "mail-dir", "каталог для писем при mail.driver: file", []string{"MYFORUM_MAIL_DIR"}},
+		{stringField{&c.Mail.SMTPAddr}, "smtp-addr", "адрес SMTP-сервера host:port", []string{"MYFORUM_SMTP_ADDR"}},
+		{stringField{&c.Mail.SMTPUsername}, "smtp-username", "имя пользователя SMTP", []string{"MYFORUM_SMTP_USERNAME"}},
+		{stringField{&c.Mail.SMTPPassword}, "smtp-password", "пароль SMTP", []string{"MYFORUM_SMTP_PASSWORD"}},
+		{stringField{&c.Login.Store}, "login-store", "хранилище счетчиков неудачных входов: memory или postgres", []string{"MYFORUM_LOGIN_STORE"}},
+		{intField{&c.Login.MaxFailures}, "login-max-failures", "неудачных входов под одним именем до блокировки, 0 — без блокировки", []string{"MYFORUM_LOGIN_MAX_FAILURES"}},
+		{intField{&c.Login.MaxFailuresPerIP}, "login-max-failures-per-ip", "неудачных входов с одного IP до блокировки, 0 — без блокировки", []string{"MYFORUM_LOGIN_MAX_FAILURES_PER_IP"}},
+		{durationField{&c.Login.Window}, "login-window", "через сколько забываются неудачные входы, например 15m", []string{"MYFORUM_LOGIN_WINDOW"}},
+		{durationField{&c.Login.Delay}, "login-delay", "пауза после первого неудачного входа, удваивается с каждым следующим", []string{"MYFORUM_LOGIN_DELAY"}},
+		{durationField{&c.Login.LockoutDuration}, "login-lockout", "на сколько блокируется вход, например 15m", []string{"MYFORUM_LOGIN_LOCKOUT"}},
+		{boolField{&c.Login.TrustProxy}, "login-trust-proxy", "брать IP клиента из X-Forwarded-For", []string{"MYFORUM_LOGIN_TRUST_PROXY"}},
+		{durationField{&c.Trash.Retention}, "trash-retention", "сколько хранить удаленное в корзине, например 720h", []string{"MYFORUM_TRASH_RETENTION"}},
+		{durationField{&c.Trash.PurgeInterval}, "purge-interval", "период очистки корзины в cmd/purge, 0 — один раз", []string{"MYFORUM_TRASH_PURGE_INTERVAL"}},
+		{stringField{&c.Paths.Templates}, "templates", "glob-шаблон HTML-шаблонов", []string{"MYFORUM_TEMPLATES"}},
+		{stringField{&c.Paths.Static}, "static-dir", "каталог статических файлов", []string{"MYFORUM_STATIC_DIR"}},
+		{stringField{&c.Paths.Migrations}, "migrations-dir", "каталог миграций", []string{"MYFORUM_MIGRATIONS_DIR"}},
+	}
+}
+
+// Load собирает конфигурацию для программы name из файла, окружения и
+// аргументов args (без имени программы) и проверяет то, что нужно этой программе.
+func Load(name string, args []string) (*Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	path := fs.String("config", "", "путь к YAML-файлу конфигурации (по умолчанию $MYFORUM_CONFIG или "+DefaultFile+")")
+	flags := make(map[string]*rawFlag)
+	for _, s := range cfg.settings() {
+		_, isBool := s.field.(boolField)
+		flags[s.flag] = &rawFlag{isBool: isBool}
+		fs.Var(flags[s.flag], s.flag, s.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	file, required := *path, true
+	if file == "" {
+		file = os.Getenv("MYFORUM_CONFIG")
+	}
+	if file == "" {
+		file, required = DefaultFile, false
+	}
+	if err := cfg.loadFile(file, required); err != nil {
+		return nil, err
+	}
+
+	for _, s := range cfg.settings() {
+		for _, env := range s.env {
+			if v, ok := os.LookupEnv(env); ok && v != "" {
+				if err := s.field.Set(v); err != nil {
+					return nil, fmt.Errorf("%s: %w", env, err)
+				}
+				break
+			}
+		}
+	}
+
+	// Флаги применяются, только если заданы явно
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range cfg.settings() {
+			if s.flag == f.Name && flagErr == nil {
+				if err := s.field.Set(flags[f.Name].value); err != nil {
+					flagErr = fmt.Errorf("-%s: %w", f.Name, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	if err := cfg.ValidateFor(name); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string, required bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !required && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return nil
+}
+
+// programChecks — проверки для каждой программы: migrate и purge не должны
+// требовать ключей JWT, почты и адресов сервисов, которыми не пользуются.
+var programChecks = map[string][]func(*Config) []error{
+	"migrate":       {(*Config).checkDatabase, (*Config).checkMigrations},
+	"purge":         {(*Config).checkDatabase, (*Config).checkLoginStore, (*Config).checkTrash},
+	"auth":          {(*Config).checkDatabase, (*Config).checkTokens, (*Config).checkIssue, (*Config).checkAuthAddr, (*Config).checkAccount, (*Config).checkLogin, (*Config).checkTemplates},
+	"forum_service": {(*Config).checkDatabase, (*Config).checkTokens, (*Config).checkForumAddrs, (*Config).checkWebSocket, (*Config).checkAccount, (*Config).checkLogin, (*Config).checkTemplates},
+}
+
+// allChecks — все проверки, для Validate и программ без своего списка
+var allChecks = []func(*Config) []error{
+	(*Config).checkDatabase, (*Config).checkTokens, (*Config).checkIssue, (*Config).checkAuthAddr,
+	(*Config).checkForumAddrs, (*Config).checkWebSocket, (*Config).checkAccount, (*Config).checkLogin,
+	(*Config).checkTrash, (*Config).checkTemplates, (*Config).checkMigrations,
+}
+
+// Validate проверяет обязательные значения и формат адресов всей конфигурации
+func (c *Config) Validate() error {
+	return c.validate(allChecks)
+}
+
+// ValidateFor проверяет только значения, которые использует программа name
+func (c *Config) ValidateFor(name string) error {
+	checks, ok := programChecks[name]
+	if !ok {
+		checks = allChecks
+	}
+	return c.validate(checks)
+}
+
+func (c *Config) validate(checks []func(*Config) []error) error {
+	var errs []error
+	for _, check := range checks {
+		errs = append(errs, check(c)...)
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Config) checkDatabase() []error {
+	if c.Database.DSN == "" {
+		return []error{errors.New("database.dsn is required")}
+	}
+	return nil
+}
+
+// checkTokens — ключи и параметры проверки токенов
+func (c *Config) checkTokens() []error {
+	var errs []error
+	if c.Auth.JWTSecret == "" && c.Auth.SigningKeyFile == "" && c.Auth.JWKSURL == "" && len(c.Auth.VerificationKeyFiles) == 0 {
+		errs = append(errs, errors.New("auth.jwt_secret is required unless auth.signing_key_file, auth.verification_key_files or auth.jwks_url is set"))
+	}
+	if c.Auth.JWKSRefresh <= 0 {
+		errs = append(errs, errors.New("auth.jwks_refresh must be positive"))
+	}
+	if c.Auth.Issuer == "" || c.Auth.Audience == "" {
+		errs = append(errs, errors.New("auth.issuer and auth.audience are required"))
+	}
+	return errs
+}
+
+// checkIssue — сроки жизни выдаваемых токенов
+func (c *Config) checkIssue() []error {
+	var errs []error
+	if c.Auth.AccessTokenTTL <= 0 || c.Auth.RefreshTokenTTL <= 0 {
+		errs = append(errs, errors.New("auth.access_token_ttl and auth.refresh_token_ttl must be positive"))
+	}
+	if c.Auth.RefreshTokenTTL < c.Auth.AccessTokenTTL {
+		errs = append(errs, errors.New("auth.refresh_token_ttl must not be shorter than auth.access_token_ttl"))
+	}
+	return errs
+}
+
+func (c *Config) checkAuthAddr() []error {
+	return checkAddrs(map[string]string{"auth.addr": c.Auth.Addr})
+}
+
+func (c *Config) checkForumAddrs() []error {
+	return checkAddrs(map[string]string{
+		"http.addr": c.HTTP.Addr,
+		"grpc.addr": c.GRPC.Addr,
+	})
+}
+
+func checkAddrs(addrs map[string]string) []error {
+	var errs []error
+	for name, addr := range addrs {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid address %q", name, addr))
+		}
+	}
+	return errs
+}
+
+func (c *Config) checkWebSocket() []error {
+	switch c.WebSocket.Broadcast {
+	case "local", "postgres":
+		return nil
+	default:
+		return []error{fmt.Errorf("websocket.broadcast: unknown driver %q", c.WebSocket.Broadcast)}
+	}
+}
+
+// checkAccount — письма для сброса пароля и подтверждения email
+func (c *Config) checkAccount() []error {
+	var errs []error
+	if c.Auth.PasswordResetTTL <= 0 || c.Auth.EmailVerificationTTL <= 0 {
+		errs = append(errs, errors.New("auth.password_reset_ttl and auth.email_verification_ttl must be positive"))
+	}
+	if c.Auth.PublicURL == "" {
+		errs = append(errs, errors.New("auth.public_url is required"))
+	}
+	switch c.Mail.Driver {
+	case "file":
+		if c.Mail.Dir == "" {
+			errs = append(errs, errors.New("mail.dir is required for mail.driver file"))
+		}
+	case "smtp":
+		if _, _, err := net.SplitHostPort(c.Mail.SMTPAddr); err != nil {
+			errs = append(errs, fmt.Errorf("mail.smtp_addr: invalid address %q", c.Mail.SMTPAddr))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("mail.driver: unknown driver %q", c.Mail.Driver))
+	}
+	return errs
+}
+
+func (c *Config) checkLoginStore() []error {
+	switch c.Login.Store {
+	case "memory", "postgres":
+		return nil
+	default:
+		return []error{fmt.Errorf("login.store: unknown store %q", c.Login.Store)}
+	}
+}
+
+func (c *Config) checkLogin() []error {
+	errs := c.checkLoginStore()
+	if c.Login.MaxFailures < 0 || c.Login.MaxFailuresPerIP < 0 || c.Login.Delay < 0 {
+		errs = append(errs, errors.New("login.max_failures, login.max_failures_per_ip and login.delay must not be negative"))
+	}
+	if c.Login.Window <= 0 || c.Login.LockoutDuration <= 0 {
+		errs = append(errs, errors.New("login.window and login.lockout_duration must be positive"))
+	}
+	return errs
+}
+
+func (c *Config) checkTrash() []error {
+	var errs []error
+	if c.Trash.Retention <= 0 {
+		errs = append(errs, errors.New("trash.retention must be positive"))
+	}
+	if c.Trash.PurgeInterval < 0 {
+		errs = append(errs, errors.New("trash.purge_interval must not be negative"))
+	}
+	return errs
+}
+
+func (c *Config) checkTemplates() []error {
+	if c.Paths.Templates == "" {
+		return []error{errors.New("paths.templates is required")}
+	}
+	return nil
+}
+
+func (c *Config) checkMigrations() []error {
+	if c.Paths.Migrations == "" {
+		return []error{errors.New("paths.migrations is required")}
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// clearEnv скрывает переменные окружения машины, на которой идут тесты
+func clearEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("MYFORUM_CONFIG", "")
+	for _, s := range Default().settings() {
+		for _, env := range s.env {
+			t.Setenv(env, "")
+		}
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, `
+database:
+  dsn: postgres://file
+http:
+  addr: ":8000"
+grpc:
+  addr: ":9000"
+auth:
+  jwt_secret: from-file
+`)
+	t.Setenv("MYFORUM_GRPC_ADDR", ":9100")
+	t.Setenv("JWT_SECRET", "legacy-env")
+	t.Setenv("MYFORUM_JWT_SECRET", "from-env")
+
+	cfg, err := Load("test", []string{"-config", path, "-http-addr", "127.0.0.1:8100"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.Database.DSN != "postgres://file" {
+		t.Errorf("dsn = %q, want value from file", cfg.Database.DSN)
+	}
+	if cfg.GRPC.Addr != ":9100" {
+		t.Errorf("grpc addr = %q, want env override", cfg.GRPC.Addr)
+	}
+	if cfg.Auth.JWTSecret != "from-env" {
+		t.Errorf("jwt secret = %q, want MYFORUM_JWT_SECRET over JWT_SECRET", cfg.Auth.JWTSecret)
+	}
+	if cfg.HTTP.Addr != "127.0.0.1:8100" {
+		t.Errorf("http addr = %q, want flag override", cfg.HTTP.Addr)
+	}
+	if cfg.Paths.Templates != "templates/*.html" {
+		t.Errorf("templates = %q, want default", cfg.Paths.Templates)
+	}
+}
+
+func TestLoadValidation(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("MYFORUM_CONFIG", writeConfig(t, "http:\n  addr: localhost\n"))
+
+	_, err := Load("test", nil)
+	if err == nil {
+		t.Fatal("Load succeeded without dsn and jwt secret")
+	}
+	for _, want := range []string{"database.dsn is required", "auth.jwt_secret is required", `http.addr: invalid address "localhost"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	path := writeConfig(t, "database:\n  dns: postgres://typo\n")
+	if _, err := Load("test", []string{"-config", path}); err == nil {
+		t.Fatal("Load accepted an unknown field")
+	}
+}
+
+func TestLoadMissingExplicitFile(t *testing.T) {
+	if _, err := Load("test", []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")}); err == nil {
+		t.Fatal("Load accepted a missing config file")
+	}
+}
+
+func TestLoadBoolSetting(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("MYFORUM_DB_DSN", "postgres://env")
+	t.Setenv("MYFORUM_JWT_SECRET", "secret")
+	path := writeConfig(t, "websocket:\n  allow_anonymous: false\n")
+
+	cfg, err := Load("test", []string{"-config", path, "-ws-allow-anonymous"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !cfg.WebSocket.AllowAnonymous {
+		t.Error("bare -ws-allow-anonymous flag did not enable anonymous mode")
+	}
+
+	t.Setenv("MYFORUM_WS_ALLOW_ANONYMOUS", "maybe")
+	if _, err := Load("test", []string{"-config", path}); err == nil || !strings.Contains(err.Error(), "MYFORUM_WS_ALLOW_ANONYMOUS") {
+		t.Errorf("invalid boolean env value: got error %v", err)
+	}
+}
+
+func TestLoadDurationSetting(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("MYFORUM_DB_DSN", "postgres://env")
+	t.Setenv("MYFORUM_JWT_SECRET", "secret")
+	path := writeConfig(t, "trash:\n  retention: 168h\n  purge_interval: 1h\n")
+
+	cfg, err := Load("test", []string{"-config", path})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Trash.Retention != 7*24*time.Hour || cfg.Trash.PurgeInterval != time.Hour {
+		t.Errorf("trash = %+v, want values from file", cfg.Trash)
+	}
+
+	cfg, err = Load("test", []string{"-config", path, "-purge-interval", "0"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Trash.PurgeInterval != 0 {
+		t.Errorf("purge interval = %v, want flag override", cfg.Trash.PurgeInterval)
+	}
+
+	t.Setenv("MYFORUM_TRASH_RETENTION", "-1h")
+	if _, err := Load("test", []string{"-config", path}); err == nil || !strings.Contains(err.Error(), "trash.retention must be positive") {
+		t.Errorf("negative retention: got error %v", err)
+	}
+}
+
+func TestLoadTokenTTL(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("MYFORUM_DB_DSN", "postgres://env")
+	t.Setenv("MYFORUM_JWT_SECRET", "secret")
+	path := writeConfig(t, "auth:\n  access_token_ttl: 5m\n")
+
+	cfg, err := Load("test", []string{"-config", path})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Auth.AccessTokenTTL != 5*time.Minute || cfg.Auth.RefreshTokenTTL != 30*24*time.Hour {
+		t.Errorf("token ttl = %v/%v, want 5m from file and default refresh", cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL)
+	}
+
+	if _, err := Load("test", []string{"-config", path, "-refresh-token-ttl", "1m"}); err == nil || !strings.Contains(err.Error(), "auth.refresh_token_ttl must not be shorter") {
+		t.Errorf("refresh shorter than access: got error %v", err)
+	}
+}
+
+func TestLoadJWTKeys(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("MYFORUM_DB_DSN", "postgres://env")
+	t.Setenv("MYFORUM_JWT_VERIFICATION_KEYS", "old.pem, older.pem")
+
+	// Ключи заменяют общий секрет
+	cfg, err := Load("test", []string{"-jwks-url", "http://auth/.well-known/jwks.json"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := cfg.Auth.VerificationKeyFiles; len(got) != 2 || got[0] != "old.pem" || got[1] != "older.pem" {
+		t.Errorf("verification keys = %q", got)
+	}
+	if cfg.Auth.JWKSRefresh != 5*time.Minute {
+		t.Errorf("jwks refresh = %v, want default 5m", cfg.Auth.JWKSRefresh)
+	}
+}
+
+func TestLoadLogin(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("MYFORUM_DB_DSN", "postgres://env")
+	t.Setenv("MYFORUM_JWT_SECRET", "secret")
+	t.Setenv("MYFORUM_LOGIN_MAX_FAILURES", "3")
+
+	cfg, err := Load("test", []string{"-login-store", "postgres", "-login-trust-proxy"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Login.Store != "postgres" || cfg.Login.MaxFailures != 3 || cfg.Login.MaxFailuresPerIP != 50 || !cfg.Login.TrustProxy {
+		t.Errorf("login = %+v", cfg.Login)
+	}
+
+	if _, err := Load("test", []string{"-login-store", "redis"}); err == nil || !strings.Contains(err.Error(), "login.store") {
+		t.Errorf("unknown store: got error %v", err)
+	}
+	if _, err := Load("test", []string{"-login-max-failures", "many"}); err == nil {
+		t.Error("non-numeric max failures accepted")
+	}
+}
+
+func TestLoadMail(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("MYFORUM_DB_DSN", "postgres://env")
+	t.Setenv("MYFORUM_JWT_SECRET", "secret")
+
+	cfg, err := Load("test", nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Mail.Driver != "file" || cfg.Auth.PasswordResetTTL != time.Hour {
+		t.Errorf("mail defaults = %+v, reset ttl %v", cfg.Mail, cfg.Auth.PasswordResetTTL)
+	}
+
+	if _, err := Load("test", []string{"-mail-driver", "smtp"}); err == nil || !strings.Contains(err.Error(), "mail.smtp_addr: invalid address") {
+		t.Errorf("smtp without address: got error %v", err)
+	}
+	if _, err := Load("test", []string{"-mail-driver", "smtp", "-smtp-addr", "mail.example.com:587"}); err != nil {
+		t.Errorf("smtp: %v", err)
+	}
+}
+
+func TestLoadValidatesPerProgram(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("MYFORUM_DB_DSN", "postgres://env")
+	// Настройки, которые migrate и purge не используют, могут быть любыми
+	t.Setenv("MYFORUM_MAIL_DRIVER", "smtp")
+	t.Setenv("MYFORUM_HTTP_ADDR", "localhost")
+
+	for _, name := range []string{"migrate", "purge"} {
+		if _, err := Load(name, nil); err != nil {
+			t.Errorf("Load(%q) with only dsn: %v", name, err)
+		}
+	}
+	if _, err := Load("migrate", []string{"-migrations-dir", ""}); err == nil || !strings.Contains(err.Error(), "paths.migrations is required") {
+		t.Errorf("migrate without migrations dir: got error %v", err)
+	}
+	if _, err := Load("purge", []string{"-trash-retention", "0s"}); err == nil || !strings.Contains(err.Error(), "trash.retention must be positive") {
+		t.Errorf("purge without retention: got error %v", err)
+	}
+
+	_, err := Load("forum_service", nil)
+	for _, want := range []string{"auth.jwt_secret is required", "mail.smtp_addr", `http.addr: invalid address "localhost"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("forum_service error %v does not mention %q", err, want)
+		}
+	}
+	// Адрес HTTP форума сервису авторизации не нужен
+	if _, err := Load("auth", []string{"-jwt-secret", "secret", "-mail-driver", "file"}); err != nil {
+		t.Errorf("Load(auth): %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package events
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/broadcast"
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+// Типы событий форума
+const (
+	MessageCreated = "message_created"
+	MessageUpdated = "message_updated"
+	MessageDeleted = "message_deleted"
+	ForumUpdated   = "forum_updated"
+	ForumDeleted   = "forum_deleted"
+)
+
+// Размер буфера подписчика: медленный подписчик теряет события, а не тормозит публикацию
+const subscriberBuffer = 64
+
+// RelayRoom — комната broadcaster, через которую шины экземпляров обмениваются
+// событиями. С WebSocket-комнатами не пересекается: их имена строит сервер.
+const RelayRoom = "events"
+
+type Event struct {
+	Type       string
+	ForumID    int
+	Message    *business.Message // для message_created и message_updated
+	MessageID  int               // для message_deleted
+	Forum      *business.Forum   // для forum_updated; forum_deleted несет только ForumID
+	OccurredAt time.Time
+}
+
+// Bus раздает события форума всем подписчикам этого форума
+type Bus struct {
+	mu    sync.RWMutex
+	subs  map[int]map[chan Event]struct{} // forumID -> подписчики
+	relay broadcast.Broadcaster
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subs: make(map[int]map[chan Event]struct{}),
+	}
+}
+
+// Subscribe подписывает на события форума. Вызывающий обязан вызвать cancel,
+// после чего канал закрывается.
+func (b *Bus) Subscribe(forumID int) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subs[forumID] == nil {
+		b.subs[forumID] = make(map[chan Event]struct{})
+	}
+	b.subs[forumID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs[forumID], ch)
+			if len(b.subs[forumID]) == 0 {
+				delete(b.subs, forumID)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+// Relay пускает события через broadcaster: Publish доставляет их подписчикам
+// шин на всех экземплярах, включая текущий. Без Relay события не покидают процесс.
+func (b *Bus) Relay(br broadcast.Broadcaster) {
+	b.mu.Lock()
+	b.relay = br
+	b.mu.Unlock()
+
+	br.Subscribe(func(room string, data []byte) {
+		if room != RelayRoom {
+			return
+		}
+		var e Event
+		if err := json.Unmarshal(data, &e); err != nil {
+			log.Printf("Event relay decode error: %v", err)
+			return
+		}
+		b.deliver(e)
+	})
+}
+
+// Publish отправляет событие подписчикам форума, не блокируясь
+func (b *Bus) Publish(e Event) {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	relay := b.relay
+	b.mu.RUnlock()
+	if relay != nil {
+		data, err := json.Marshal(e)
+		if err == nil {
+			err = relay.Publish(RelayRoom, data)
+		}
+		if err == nil {
+			return
+		}
+		// Другие экземпляры событие не получат, но свои подписчики — да
+		log.Printf("Event relay failed: %v", err)
+	}
+	b.deliver(e)
+}
+
+// deliver раздает событие подписчикам этого процесса
+func (b *Bus) deliver(e Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subs[e.ForumID] {
+		select {
+		case ch <- e:
+		default:
+			log.Printf("Event %s for forum %d dropped: subscriber is too slow", e.Type, e.ForumID)
+		}
+	}
+}
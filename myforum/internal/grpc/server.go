@@ -0,0 +1,536 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/events"
+	pb "github.com/jaxxiy/myforum/internal/grpc/proto"
+	"github.com/jaxxiy/myforum/internal/rbac"
+	"github.com/jaxxiy/myforum/pkg/jwt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Прежние имена действий CheckUserPermission. Они по-прежнему принимаются
+// наравне с именами прав rbac (forum.update, message.delete.any, ...).
+const (
+	ActionEditMessage   = "edit_message"
+	ActionDeleteMessage = "delete_message"
+	ActionUpdateForum   = "update_forum"
+	ActionDeleteForum   = "delete_forum"
+)
+
+var legacyActions = map[string]string{
+	ActionEditMessage:   rbac.MessageUpdate.Name,
+	ActionDeleteMessage: rbac.MessageDelete.Name,
+	ActionUpdateForum:   string(rbac.ForumUpdate),
+	ActionDeleteForum:   string(rbac.ForumDelete),
+}
+
+// messageActions зависят от автора сообщения, поэтому требуют resource_id
+var messageActions = map[string]rbac.OwnedAction{
+	rbac.MessageUpdate.Name: rbac.MessageUpdate,
+	rbac.MessageDelete.Name: rbac.MessageDelete,
+}
+
+// Repository — методы ForumsRepo, нужные gRPC-сервису
+type Repository interface {
+	Create(f business.Forum) (int, error)
+	GetAll() ([]business.Forum, error)
+	GetByID(id int) (*business.Forum, error)
+	Update(id int, f business.Forum) error
+	Delete(id int, deletedBy int) error
+	IsForumModerator(forumID, userID int) (bool, error)
+
+	CreateMessage(msg business.Message) (int, error)
+	GetMessages(forumID int) ([]business.Message, error)
+	GetMessageByID(messageID int) (*business.Message, error)
+	PutMessage(messageID int, updatedContent string, editorID int) (*business.Message, error)
+	DeleteMessage(id int, deletedBy int) error
+
+	GetUserByID(userID int) (*business.User, error)
+	IsAccessTokenRevoked(jti string, userID int, issuedAt time.Time) (bool, error)
+}
+
+type ForumServer struct {
+	pb.UnimplementedForumServiceServer
+	repo   Repository
+	tokens *jwt.Manager
+	bus    *events.Bus
+}
+
+func NewForumServer(repo Repository, tokens *jwt.Manager, bus *events.Bus) *ForumServer {
+	return &ForumServer{
+		repo:   repo,
+		tokens: tokens,
+		bus:    bus,
+	}
+}
+
+// CheckUserPermission отвечает, может ли пользователь выполнить действие.
+// Решение принимает rbac, как и в HTTP-хендлерах. Для message.update и
+// message.delete нужен resource_id сообщения: от автора зависит, хватит ли
+// права .own. Для прав на форум resource_id необязателен, но если он
+// передан, форум должен существовать.
+func (s *ForumServer) CheckUserPermission(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+	userID, err := strconv.Atoi(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user_id")
+	}
+
+	action := req.GetAction()
+	if name, ok := legacyActions[action]; ok {
+		action = name
+	}
+	ownedAction, owned := messageActions[action]
+
+	var resourceID int
+	if req.GetResourceId() != "" || owned {
+		resourceID, err = strconv.Atoi(req.GetResourceId())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid resource_id")
+		}
+	}
+
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		// Неизвестному пользователю ничего не разрешено
+		return &pb.PermissionResponse{Allowed: false}, nil
+	}
+
+	if owned {
+		msg, err := s.repo.GetMessageByID(resourceID)
+		if err != nil {
+			return nil, status.Error(codes.NotFound, "message not found")
+		}
+		forum, err := s.repo.GetByID(msg.ForumID)
+		if err != nil {
+			return nil, status.Error(codes.NotFound, "forum not found")
+		}
+		allowed := rbac.CanOwnedInForum(user, ownedAction, msg.AuthorID, s.forumAccess(forum, user))
+		// Сообщения архивного форума можно удалить, но не исправить
+		if ownedAction == rbac.MessageUpdate && !rbac.Writable(forum) {
+			allowed = false
+		}
+		return &pb.PermissionResponse{Allowed: allowed}, nil
+	}
+
+	perm := rbac.Permission(action)
+	if !rbac.IsPermission(perm) {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown action %q", req.GetAction())
+	}
+	if req.GetResourceId() != "" && strings.HasPrefix(action, "forum.") {
+		forum, err := s.repo.GetByID(resourceID)
+		if err != nil {
+			return nil, status.Error(codes.NotFound, "forum not found")
+		}
+		return &pb.PermissionResponse{Allowed: rbac.CanInForum(user, perm, s.forumAccess(forum, user))}, nil
+	}
+	return &pb.PermissionResponse{Allowed: rbac.Can(user, perm)}, nil
+}
+
+//Форумы
+
+// ListForums возвращает форумы, видимые пользователю: форумы для участников
+// без токена не показываются
+func (s *ForumServer) ListForums(ctx context.Context, req *pb.ListForumsRequest) (*pb.ListForumsResponse, error) {
+	user, err := s.optionalUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	forums, err := s.repo.GetAll()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &pb.ListForumsResponse{}
+	for _, f := range forums {
+		if rbac.CanReadForum(user, &f) {
+			resp.Forums = append(resp.Forums, toProtoForum(&f))
+		}
+	}
+	return resp, nil
+}
+
+func (s *ForumServer) GetForum(ctx context.Context, req *pb.GetForumRequest) (*pb.Forum, error) {
+	user, err := s.optionalUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	forum, err := s.readableForum(int(req.GetId()), user)
+	if err != nil {
+		return nil, err
+	}
+	return toProtoForum(forum), nil
+}
+
+func (s *ForumServer) CreateForum(ctx context.Context, req *pb.CreateForumRequest) (*pb.Forum, error) {
+	user, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !rbac.Can(user, rbac.ForumCreate) {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+	if strings.TrimSpace(req.GetTitle()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "title is required")
+	}
+	visibility := req.GetVisibility()
+	if visibility == "" {
+		visibility = business.VisibilityPublic
+	}
+	if !business.ValidVisibility(visibility) {
+		return nil, status.Error(codes.InvalidArgument, "invalid visibility")
+	}
+
+	// Создатель становится владельцем форума
+	forum := business.Forum{
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		OwnerID:     user.ID,
+		Visibility:  visibility,
+		CreatedAt:   time.Now(),
+	}
+	id, err := s.repo.Create(forum)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	forum.ID = id
+
+	return toProtoForum(&forum), nil
+}
+
+func (s *ForumServer) UpdateForum(ctx context.Context, req *pb.UpdateForumRequest) (*pb.Forum, error) {
+	user, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	forum, err := s.repo.GetByID(int(req.GetId()))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "forum not found")
+	}
+	if !rbac.CanInForum(user, rbac.ForumUpdate, s.forumAccess(forum, user)) {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+	if v := req.GetVisibility(); v != "" {
+		if !business.ValidVisibility(v) {
+			return nil, status.Error(codes.InvalidArgument, "invalid visibility")
+		}
+		forum.Visibility = v
+	}
+	// Меняются только заданные поля, как в PATCH
+	if req.Title != nil {
+		if strings.TrimSpace(req.GetTitle()) == "" {
+			return nil, status.Error(codes.InvalidArgument, "title is required")
+		}
+		forum.Title = req.GetTitle()
+	}
+	if req.Description != nil {
+		forum.Description = req.GetDescription()
+	}
+
+	if err := s.repo.Update(forum.ID, *forum); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	s.bus.Publish(events.Event{Type: events.ForumUpdated, ForumID: forum.ID, Forum: forum})
+
+	return toProtoForum(forum), nil
+}
+
+func (s *ForumServer) DeleteForum(ctx context.Context, req *pb.DeleteForumRequest) (*pb.DeleteForumResponse, error) {
+	user, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	forum, err := s.repo.GetByID(int(req.GetId()))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "forum not found")
+	}
+	if !rbac.CanInForum(user, rbac.ForumDelete, s.forumAccess(forum, user)) {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	if err := s.repo.Delete(forum.ID, user.ID); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	s.bus.Publish(events.Event{Type: events.ForumDeleted, ForumID: forum.ID})
+
+	return &pb.DeleteForumResponse{}, nil
+}
+
+//Сообщения
+
+func (s *ForumServer) ListMessages(ctx context.Context, req *pb.ListMessagesRequest) (*pb.ListMessagesResponse, error) {
+	user, err := s.optionalUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.readableForum(int(req.GetForumId()), user); err != nil {
+		return nil, err
+	}
+
+	messages, err := s.repo.GetMessages(int(req.GetForumId()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &pb.ListMessagesResponse{}
+	for _, m := range messages {
+		resp.Messages = append(resp.Messages, toProtoMessage(&m))
+	}
+	return resp, nil
+}
+
+func (s *ForumServer) CreateMessage(ctx context.Context, req *pb.CreateMessageRequest) (*pb.Message, error) {
+	user, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !rbac.Can(user, rbac.MessageCreateOwn) {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+	if strings.TrimSpace(req.GetContent()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "content is required")
+	}
+	if _, err := s.writableForum(int(req.GetForumId()), user); err != nil {
+		return nil, err
+	}
+
+	// Автор всегда берется из токена
+	msg := business.Message{
+		ForumID:   int(req.GetForumId()),
+		AuthorID:  user.ID,
+		Author:    user.Username,
+		Content:   req.GetContent(),
+		CreatedAt: time.Now(),
+	}
+	id, err := s.repo.CreateMessage(msg)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	msg.ID = id
+	s.bus.Publish(events.Event{Type: events.MessageCreated, ForumID: msg.ForumID, Message: &msg})
+
+	return toProtoMessage(&msg), nil
+}
+
+func (s *ForumServer) UpdateMessage(ctx context.Context, req *pb.UpdateMessageRequest) (*pb.Message, error) {
+	user, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.GetContent()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "content is required")
+	}
+
+	msg, err := s.repo.GetMessageByID(int(req.GetId()))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "message not found")
+	}
+	forum, err := s.writableForum(msg.ForumID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !rbac.CanOwnedInForum(user, rbac.MessageUpdate, msg.AuthorID, s.forumAccess(forum, user)) {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	updated, err := s.repo.PutMessage(msg.ID, req.GetContent(), user.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	s.bus.Publish(events.Event{Type: events.MessageUpdated, ForumID: updated.ForumID, Message: updated})
+
+	return toProtoMessage(updated), nil
+}
+
+func (s *ForumServer) DeleteMessage(ctx context.Context, req *pb.DeleteMessageRequest) (*pb.DeleteMessageResponse, error) {
+	user, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := s.repo.GetMessageByID(int(req.GetId()))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "message not found")
+	}
+	forum, err := s.readableForum(msg.ForumID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !rbac.CanOwnedInForum(user, rbac.MessageDelete, msg.AuthorID, s.forumAccess(forum, user)) {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	if err := s.repo.DeleteMessage(msg.ID, user.ID); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	s.bus.Publish(events.Event{Type: events.MessageDeleted, ForumID: msg.ForumID, MessageID: msg.ID})
+
+	return &pb.DeleteMessageResponse{}, nil
+}
+
+// SubscribeForum стримит события форума, пока клиент не отключится
+func (s *ForumServer) SubscribeForum(req *pb.SubscribeForumRequest, stream pb.ForumService_SubscribeForumServer) error {
+	forumID := int(req.GetForumId())
+	user, err := s.optionalUser(stream.Context())
+	if err != nil {
+		return err
+	}
+	if _, err := s.readableForum(forumID, user); err != nil {
+		return err
+	}
+
+	ch, cancel := s.bus.Subscribe(forumID)
+	defer cancel()
+
+	// Заголовки уходят клиенту, когда подписка уже зарегистрирована
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(toProtoEvent(e)); err != nil {
+				return err
+			}
+			// Удаленный форум больше не пришлет событий
+			if e.Type == events.ForumDeleted {
+				return nil
+			}
+		}
+	}
+}
+
+// authenticate достает пользователя по JWT из метаданных "authorization: Bearer <token>"
+func (s *ForumServer) authenticate(ctx context.Context) (*business.User, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+	}
+
+	tokenString := strings.TrimPrefix(values[0], "Bearer ")
+	claims, err := s.tokens.ParseActive(tokenString, s.repo)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	user, err := s.repo.GetUserByID(claims.UserID)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "user not found")
+	}
+	return user, nil
+}
+
+// optionalUser — как authenticate, но без метаданных authorization возвращает nil
+func (s *ForumServer) optionalUser(ctx context.Context) (*business.User, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if len(md.Get("authorization")) == 0 {
+		return nil, nil
+	}
+	return s.authenticate(ctx)
+}
+
+// forumAccess определяет, владелец ли пользователь форума или его модератор
+func (s *ForumServer) forumAccess(forum *business.Forum, user *business.User) rbac.ForumAccess {
+	if user == nil {
+		return rbac.ForumAccess{}
+	}
+	if forum.OwnerID == user.ID {
+		return rbac.ForumAccess{Owner: true}
+	}
+	isModerator, _ := s.repo.IsForumModerator(forum.ID, user.ID)
+	return rbac.ForumAccess{Moderator: isModerator}
+}
+
+// readableForum загружает форум, содержимое которого видно пользователю
+func (s *ForumServer) readableForum(forumID int, user *business.User) (*business.Forum, error) {
+	forum, err := s.repo.GetByID(forumID)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "forum not found")
+	}
+	if !rbac.CanReadForum(user, forum) {
+		if user == nil {
+			return nil, status.Error(codes.Unauthenticated, "forum is for members only")
+		}
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+	return forum, nil
+}
+
+// writableForum — как readableForum, но в архивный форум писать нельзя
+func (s *ForumServer) writableForum(forumID int, user *business.User) (*business.Forum, error) {
+	forum, err := s.readableForum(forumID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !rbac.Writable(forum) {
+		return nil, status.Error(codes.FailedPrecondition, "forum is archived")
+	}
+	return forum, nil
+}
+
+func toProtoForum(f *business.Forum) *pb.Forum {
+	return &pb.Forum{
+		Id:          int64(f.ID),
+		Title:       f.Title,
+		Description: f.Description,
+		CreatedAt:   timestamppb.New(f.CreatedAt),
+		OwnerId:     int64(f.OwnerID),
+		Visibility:  f.Visibility,
+	}
+}
+
+func toProtoMessage(m *business.Message) *pb.Message {
+	pm := &pb.Message{
+		Id:        int64(m.ID),
+		ForumId:   int64(m.ForumID),
+		Author:    m.Author,
+		Content:   m.Content,
+		CreatedAt: timestamppb.New(m.CreatedAt),
+	}
+	if m.EditedAt != nil {
+		pm.EditedAt = timestamppb.New(*m.EditedAt)
+	}
+	return pm
+}
+
+var eventTypes = map[string]pb.ForumEvent_Type{
+	events.MessageCreated: pb.ForumEvent_MESSAGE_CREATED,
+	events.MessageUpdated: pb.ForumEvent_MESSAGE_UPDATED,
+	events.MessageDeleted: pb.ForumEvent_MESSAGE_DELETED,
+	events.ForumUpdated:   pb.ForumEvent_FORUM_UPDATED,
+	events.ForumDeleted:   pb.ForumEvent_FORUM_DELETED,
+}
+
+func toProtoEvent(e events.Event) *pb.ForumEvent {
+	ev := &pb.ForumEvent{
+		Type:       eventTypes[e.Type],
+		ForumId:    int64(e.ForumID),
+		OccurredAt: timestamppb.New(e.OccurredAt),
+	}
+	switch {
+	case e.Message != nil:
+		ev.Payload = &pb.ForumEvent_Message{Message: toProtoMessage(e.Message)}
+	case e.Forum != nil:
+		ev.Payload = &pb.ForumEvent_Forum{Forum: toProtoForum(e.Forum)}
+	case e.MessageID != 0:
+		ev.Payload = &pb.ForumEvent_MessageId{MessageId: int64(e.MessageID)}
+	}
+	return ev
+}
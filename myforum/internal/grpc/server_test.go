@@ -0,0 +1,484 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/broadcast"
+	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/events"
+	pb "github.com/jaxxiy/myforum/internal/grpc/proto"
+	"github.com/jaxxiy/myforum/pkg/jwt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeRepo struct {
+	users      map[int]*business.User
+	messages   map[int]*business.Message
+	forums     map[int]*business.Forum
+	moderators map[int]map[int]bool // форум -> пользователь
+	// Пользователи, вышедшие со всех устройств: их токены отозваны
+	loggedOut map[int]bool
+	nextID    int
+}
+
+func (r *fakeRepo) newID() int {
+	r.nextID++
+	return 1000 + r.nextID
+}
+
+func (r *fakeRepo) Create(f business.Forum) (int, error) {
+	f.ID = r.newID()
+	r.forums[f.ID] = &f
+	return f.ID, nil
+}
+
+func (r *fakeRepo) GetAll() ([]business.Forum, error) {
+	var forums []business.Forum
+	for _, f := range r.forums {
+		forums = append(forums, *f)
+	}
+	return forums, nil
+}
+
+func (r *fakeRepo) GetByID(id int) (*business.Forum, error) {
+	if f, ok := r.forums[id]; ok {
+		copied := *f
+		return &copied, nil
+	}
+	return nil, errors.New("forum not found")
+}
+
+func (r *fakeRepo) Update(id int, f business.Forum) error {
+	if _, ok := r.forums[id]; !ok {
+		return errors.New("no forum found with the given ID")
+	}
+	f.ID = id
+	r.forums[id] = &f
+	return nil
+}
+
+func (r *fakeRepo) Delete(id int, deletedBy int) error {
+	if _, ok := r.forums[id]; !ok {
+		return errors.New("no forum found with the given ID")
+	}
+	delete(r.forums, id)
+	return nil
+}
+
+func (r *fakeRepo) IsForumModerator(forumID, userID int) (bool, error) {
+	return r.moderators[forumID][userID], nil
+}
+
+func (r *fakeRepo) IsAccessTokenRevoked(jti string, userID int, issuedAt time.Time) (bool, error) {
+	return r.loggedOut[userID], nil
+}
+
+func (r *fakeRepo) CreateMessage(msg business.Message) (int, error) {
+	msg.ID = r.newID()
+	r.messages[msg.ID] = &msg
+	return msg.ID, nil
+}
+
+func (r *fakeRepo) GetMessages(forumID int) ([]business.Message, error) {
+	var messages []business.Message
+	for _, m := range r.messages {
+		if m.ForumID == forumID {
+			messages = append(messages, *m)
+		}
+	}
+	return messages, nil
+}
+
+func (r *fakeRepo) GetMessageByID(id int) (*business.Message, error) {
+	if m, ok := r.messages[id]; ok {
+		copied := *m
+		return &copied, nil
+	}
+	return nil, errors.New("message not found")
+}
+
+func (r *fakeRepo) PutMessage(id int, content string, editorID int) (*business.Message, error) {
+	m, ok := r.messages[id]
+	if !ok {
+		return nil, errors.New("message not found")
+	}
+	now := time.Now()
+	m.Content = content
+	m.EditedAt = &now
+	copied := *m
+	return &copied, nil
+}
+
+func (r *fakeRepo) DeleteMessage(id int, deletedBy int) error {
+	delete(r.messages, id)
+	return nil
+}
+
+func (r *fakeRepo) GetUserByID(id int) (*business.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, errors.New("user not found")
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		users: map[int]*business.User{
+			1: {ID: 1, Username: "alice", Role: "user"},
+			2: {ID: 2, Username: "bob", Role: "user"},
+			3: {ID: 3, Username: "root", Role: "admin"},
+			4: {ID: 4, Username: "mod", Role: "moderator"},
+			5: {ID: 5, Username: "reader", Role: "read-only"},
+		},
+		messages: map[int]*business.Message{
+			10: {ID: 10, ForumID: 5, AuthorID: 1, Author: "alice", Content: "hi"},
+			11: {ID: 11, ForumID: 6, AuthorID: 1, Author: "alice", Content: "old news"},
+		},
+		forums: map[int]*business.Forum{
+			5: {ID: 5, Title: "General", Visibility: business.VisibilityPublic},
+			6: {ID: 6, Title: "Archive", OwnerID: 2, Visibility: business.VisibilityArchived},
+		},
+		moderators: map[int]map[int]bool{},
+	}
+}
+
+var testTokens, _ = jwt.NewManager(jwt.Config{Key: "test-secret"})
+
+// withToken кладет JWT пользователя в исходящие метаданные
+func withToken(t *testing.T, userID int) context.Context {
+	t.Helper()
+	token, err := testTokens.Issue(jwt.Claims{UserID: userID}, time.Hour)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func newTestClient(t *testing.T, repo Repository) pb.ForumServiceClient {
+	t.Helper()
+	return newTestClientWithBus(t, repo, events.NewBus())
+}
+
+func newTestClientWithBus(t *testing.T, repo Repository, bus *events.Bus) pb.ForumServiceClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	pb.RegisterForumServiceServer(srv, NewForumServer(repo, testTokens, bus))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewForumServiceClient(conn)
+}
+
+func TestCheckUserPermission(t *testing.T) {
+	client := newTestClient(t, newFakeRepo())
+
+	tests := []struct {
+		name     string
+		req      *pb.PermissionRequest
+		allowed  bool
+		wantCode codes.Code
+	}{
+		{"author edits own message", &pb.PermissionRequest{UserId: "1", Action: ActionEditMessage, ResourceId: "10"}, true, codes.OK},
+		{"author deletes own message", &pb.PermissionRequest{UserId: "1", Action: ActionDeleteMessage, ResourceId: "10"}, true, codes.OK},
+		{"other user edits message", &pb.PermissionRequest{UserId: "2", Action: ActionEditMessage, ResourceId: "10"}, false, codes.OK},
+		{"admin deletes message", &pb.PermissionRequest{UserId: "3", Action: ActionDeleteMessage, ResourceId: "10"}, true, codes.OK},
+		{"user updates forum", &pb.PermissionRequest{UserId: "1", Action: ActionUpdateForum, ResourceId: "5"}, false, codes.OK},
+		{"admin deletes forum", &pb.PermissionRequest{UserId: "3", Action: ActionDeleteForum, ResourceId: "5"}, true, codes.OK},
+		{"moderator edits message", &pb.PermissionRequest{UserId: "4", Action: "message.update", ResourceId: "10"}, true, codes.OK},
+		{"moderator updates forum", &pb.PermissionRequest{UserId: "4", Action: ActionUpdateForum, ResourceId: "5"}, true, codes.OK},
+		{"moderator deletes forum", &pb.PermissionRequest{UserId: "4", Action: ActionDeleteForum, ResourceId: "5"}, false, codes.OK},
+		{"user creates forum", &pb.PermissionRequest{UserId: "1", Action: "forum.create"}, true, codes.OK},
+		{"read-only posts to chat", &pb.PermissionRequest{UserId: "5", Action: "chat.post"}, false, codes.OK},
+		{"read-only edits own message", &pb.PermissionRequest{UserId: "5", Action: ActionEditMessage, ResourceId: "10"}, false, codes.OK},
+		{"admin has message.delete.any", &pb.PermissionRequest{UserId: "3", Action: "message.delete.any"}, true, codes.OK},
+		{"message action without resource", &pb.PermissionRequest{UserId: "1", Action: "message.delete"}, false, codes.InvalidArgument},
+		{"owner deletes own forum", &pb.PermissionRequest{UserId: "2", Action: ActionDeleteForum, ResourceId: "6"}, true, codes.OK},
+		{"owner deletes message in own forum", &pb.PermissionRequest{UserId: "2", Action: ActionDeleteMessage, ResourceId: "11"}, true, codes.OK},
+		{"author edits message in archive", &pb.PermissionRequest{UserId: "1", Action: ActionEditMessage, ResourceId: "11"}, false, codes.OK},
+		{"unknown user", &pb.PermissionRequest{UserId: "42", Action: ActionEditMessage, ResourceId: "10"}, false, codes.OK},
+		{"missing message", &pb.PermissionRequest{UserId: "1", Action: ActionEditMessage, ResourceId: "99"}, false, codes.NotFound},
+		{"missing forum", &pb.PermissionRequest{UserId: "3", Action: ActionUpdateForum, ResourceId: "99"}, false, codes.NotFound},
+		{"unknown action", &pb.PermissionRequest{UserId: "1", Action: "launch", ResourceId: "10"}, false, codes.InvalidArgument},
+		{"bad user id", &pb.PermissionRequest{UserId: "abc", Action: ActionEditMessage, ResourceId: "10"}, false, codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.CheckUserPermission(context.Background(), tt.req)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %v, want %v (err: %v)", code, tt.wantCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if resp.GetAllowed() != tt.allowed {
+				t.Errorf("allowed = %v, want %v", resp.GetAllowed(), tt.allowed)
+			}
+		})
+	}
+}
+
+func TestForumCRUD(t *testing.T) {
+	client := newTestClient(t, newFakeRepo())
+
+	if _, err := client.CreateForum(context.Background(), &pb.CreateForumRequest{Title: "News"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("create without token: got %v, want Unauthenticated", err)
+	}
+
+	forum, err := client.CreateForum(withToken(t, 1), &pb.CreateForumRequest{Title: "News", Description: "Latest"})
+	if err != nil {
+		t.Fatalf("create forum: %v", err)
+	}
+
+	got, err := client.GetForum(context.Background(), &pb.GetForumRequest{Id: forum.GetId()})
+	if err != nil || got.GetTitle() != "News" {
+		t.Fatalf("get forum = %v, %v", got, err)
+	}
+
+	list, err := client.ListForums(context.Background(), &pb.ListForumsRequest{})
+	if err != nil || len(list.GetForums()) != 3 {
+		t.Fatalf("list forums = %v, %v", list, err)
+	}
+
+	_, err = client.UpdateForum(withToken(t, 2), &pb.UpdateForumRequest{Id: forum.GetId(), Title: proto.String("Hacked")})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("update by user: got %v, want PermissionDenied", err)
+	}
+
+	updated, err := client.UpdateForum(withToken(t, 3), &pb.UpdateForumRequest{Id: forum.GetId(), Title: proto.String("Breaking")})
+	if err != nil || updated.GetTitle() != "Breaking" || updated.GetDescription() != forum.GetDescription() {
+		t.Fatalf("update by admin = %v, %v", updated, err)
+	}
+	if _, err := client.UpdateForum(withToken(t, 3), &pb.UpdateForumRequest{Id: forum.GetId(), Title: proto.String("  ")}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("blank title: got %v, want InvalidArgument", err)
+	}
+	updated, err = client.UpdateForum(withToken(t, 3), &pb.UpdateForumRequest{Id: forum.GetId(), Description: proto.String("")})
+	if err != nil || updated.GetTitle() != "Breaking" || updated.GetDescription() != "" {
+		t.Fatalf("clear description = %v, %v", updated, err)
+	}
+
+	if _, err := client.DeleteForum(withToken(t, 3), &pb.DeleteForumRequest{Id: forum.GetId()}); err != nil {
+		t.Fatalf("delete forum: %v", err)
+	}
+	if _, err := client.GetForum(context.Background(), &pb.GetForumRequest{Id: forum.GetId()}); status.Code(err) != codes.NotFound {
+		t.Fatalf("get deleted forum: got %v, want NotFound", err)
+	}
+}
+
+func TestForumVisibility(t *testing.T) {
+	repo := newFakeRepo()
+	repo.forums[7] = &business.Forum{ID: 7, Title: "Club", OwnerID: 1, Visibility: business.VisibilityMembers}
+	client := newTestClient(t, repo)
+
+	if _, err := client.ListMessages(context.Background(), &pb.ListMessagesRequest{ForumId: 7}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("members forum without token: got %v, want Unauthenticated", err)
+	}
+	if _, err := client.ListMessages(withToken(t, 2), &pb.ListMessagesRequest{ForumId: 7}); err != nil {
+		t.Fatalf("members forum with token: %v", err)
+	}
+	if _, err := client.GetForum(context.Background(), &pb.GetForumRequest{Id: 7}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("get members forum without token: got %v, want Unauthenticated", err)
+	}
+	if f, err := client.GetForum(withToken(t, 2), &pb.GetForumRequest{Id: 7}); err != nil || f.GetTitle() != "Club" {
+		t.Fatalf("get members forum with token = %v, %v", f, err)
+	}
+	listed := func(ctx context.Context) bool {
+		resp, err := client.ListForums(ctx, &pb.ListForumsRequest{})
+		if err != nil {
+			t.Fatalf("list forums: %v", err)
+		}
+		for _, f := range resp.GetForums() {
+			if f.GetId() == 7 {
+				return true
+			}
+		}
+		return false
+	}
+	if listed(context.Background()) || !listed(withToken(t, 2)) {
+		t.Fatal("members forum must be listed only with a token")
+	}
+
+	_, err := client.CreateMessage(withToken(t, 1), &pb.CreateMessageRequest{ForumId: 6, Content: "late"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("post to archive: got %v, want FailedPrecondition", err)
+	}
+
+	// владелец форума может перевести его в публичный режим
+	updated, err := client.UpdateForum(withToken(t, 2), &pb.UpdateForumRequest{Id: 6, Visibility: business.VisibilityPublic})
+	if err != nil || updated.GetVisibility() != business.VisibilityPublic || updated.GetTitle() == "" {
+		t.Fatalf("owner unarchives forum = %v, %v", updated, err)
+	}
+	if _, err := client.UpdateForum(withToken(t, 2), &pb.UpdateForumRequest{Id: 6, Visibility: "hidden"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("unknown visibility: got %v, want InvalidArgument", err)
+	}
+}
+
+func TestRevokedToken(t *testing.T) {
+	repo := newFakeRepo()
+	repo.loggedOut = map[int]bool{2: true}
+	client := newTestClient(t, repo)
+
+	_, err := client.CreateMessage(withToken(t, 2), &pb.CreateMessageRequest{ForumId: 5, Content: "hello"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("revoked token: got %v, want Unauthenticated", err)
+	}
+	if _, err := client.CreateMessage(withToken(t, 1), &pb.CreateMessageRequest{ForumId: 5, Content: "hello"}); err != nil {
+		t.Fatalf("active token: %v", err)
+	}
+}
+
+func TestMessageCRUD(t *testing.T) {
+	client := newTestClient(t, newFakeRepo())
+
+	msg, err := client.CreateMessage(withToken(t, 2), &pb.CreateMessageRequest{ForumId: 5, Content: "hello"})
+	if err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+	if msg.GetAuthor() != "bob" {
+		t.Errorf("author = %q, want bob", msg.GetAuthor())
+	}
+
+	if _, err := client.CreateMessage(withToken(t, 2), &pb.CreateMessageRequest{ForumId: 99, Content: "hello"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("create in missing forum: got %v, want NotFound", err)
+	}
+
+	list, err := client.ListMessages(context.Background(), &pb.ListMessagesRequest{ForumId: 5})
+	if err != nil || len(list.GetMessages()) != 2 {
+		t.Fatalf("list messages = %v, %v", list, err)
+	}
+
+	if _, err := client.UpdateMessage(withToken(t, 1), &pb.UpdateMessageRequest{Id: msg.GetId(), Content: "edited"}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("update by other user: got %v, want PermissionDenied", err)
+	}
+
+	updated, err := client.UpdateMessage(withToken(t, 2), &pb.UpdateMessageRequest{Id: msg.GetId(), Content: "edited"})
+	if err != nil || updated.GetContent() != "edited" {
+		t.Fatalf("update by author = %v, %v", updated, err)
+	}
+
+	if _, err := client.DeleteMessage(withToken(t, 3), &pb.DeleteMessageRequest{Id: msg.GetId()}); err != nil {
+		t.Fatalf("delete by admin: %v", err)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer garbage")
+	if _, err := client.DeleteMessage(ctx, &pb.DeleteMessageRequest{Id: 10}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("delete with bad token: got %v, want Unauthenticated", err)
+	}
+}
+
+func TestSubscribeForum(t *testing.T) {
+	client := newTestClient(t, newFakeRepo())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.SubscribeForum(ctx, &pb.SubscribeForumRequest{ForumId: 5})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	// Заголовки приходят после регистрации подписки
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("header: %v", err)
+	}
+
+	msg, err := client.CreateMessage(withToken(t, 1), &pb.CreateMessageRequest{ForumId: 5, Content: "live"})
+	if err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+	if _, err := client.DeleteMessage(withToken(t, 1), &pb.DeleteMessageRequest{Id: msg.GetId()}); err != nil {
+		t.Fatalf("delete message: %v", err)
+	}
+
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("recv created: %v", err)
+	}
+	if ev.GetType() != pb.ForumEvent_MESSAGE_CREATED || ev.GetMessage().GetContent() != "live" {
+		t.Errorf("first event = %v, want MESSAGE_CREATED with content", ev)
+	}
+
+	ev, err = stream.Recv()
+	if err != nil {
+		t.Fatalf("recv deleted: %v", err)
+	}
+	if ev.GetType() != pb.ForumEvent_MESSAGE_DELETED || ev.GetMessageId() != msg.GetId() {
+		t.Errorf("second event = %v, want MESSAGE_DELETED for %d", ev, msg.GetId())
+	}
+
+	// После удаления форума поток завершается
+	if _, err := client.DeleteForum(withToken(t, 3), &pb.DeleteForumRequest{Id: 5}); err != nil {
+		t.Fatalf("delete forum: %v", err)
+	}
+	ev, err = stream.Recv()
+	if err != nil || ev.GetType() != pb.ForumEvent_FORUM_DELETED || ev.GetForumId() != 5 {
+		t.Fatalf("third event = %v, %v, want FORUM_DELETED", ev, err)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("recv after forum deleted: got %v, want EOF", err)
+	}
+
+	missing, err := client.SubscribeForum(ctx, &pb.SubscribeForumRequest{ForumId: 99})
+	if err != nil {
+		t.Fatalf("subscribe missing: %v", err)
+	}
+	if _, err := missing.Recv(); status.Code(err) != codes.NotFound {
+		t.Errorf("subscribe to missing forum: got %v, want NotFound", err)
+	}
+}
+
+// Два экземпляра с общим broadcaster: подписчик второго видит сообщение,
+// созданное через первый
+func TestSubscribeForumAcrossInstances(t *testing.T) {
+	repo := newFakeRepo()
+	b := broadcast.NewLocal()
+	busA, busB := events.NewBus(), events.NewBus()
+	busA.Relay(b)
+	busB.Relay(b)
+	clientA := newTestClientWithBus(t, repo, busA)
+	clientB := newTestClientWithBus(t, repo, busB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := clientB.SubscribeForum(ctx, &pb.SubscribeForumRequest{ForumId: 5})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("header: %v", err)
+	}
+
+	if _, err := clientA.CreateMessage(withToken(t, 1), &pb.CreateMessageRequest{ForumId: 5, Content: "from A"}); err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	if ev.GetType() != pb.ForumEvent_MESSAGE_CREATED || ev.GetMessage().GetContent() != "from A" || ev.GetOccurredAt() == nil {
+		t.Errorf("event = %v, want MESSAGE_CREATED from A", ev)
+	}
+}
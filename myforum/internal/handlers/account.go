@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/repository"
+	"github.com/jaxxiy/myforum/internal/services"
+)
+
+// RegisterAccountHandlers — аккаунт вошедшего пользователя: /api/me.
+// trustProxy — брать адрес клиента из X-Forwarded-For, как AuthHandler.
+func RegisterAccountHandlers(r *mux.Router, users repository.AccountStore, opts services.AccountOptions, trustProxy bool) {
+	accounts := services.NewAccountService(users, opts)
+	r.HandleFunc("/api/me", GetMe(users)).Methods("GET")
+	r.HandleFunc("/api/me", UpdateMe(users, accounts, trustProxy)).Methods("PATCH")
+	r.HandleFunc("/api/me", DeleteMe(users, accounts, trustProxy)).Methods("DELETE")
+}
+
+// GetMe отдает аккаунт владельца токена
+func GetMe(users repository.UserReader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		user := userFromRequest(r, users)
+		if user == nil {
+			sendError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		json.NewEncoder(w).Encode(user)
+	}
+}
+
+// UpdateMe меняет профиль, email или пароль; поля, которых нет в теле, не меняются.
+// После смены пароля токен запроса отозван: нужно войти заново.
+func UpdateMe(users repository.UserReader, accounts *services.AccountService, trustProxy bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		user := userFromRequest(r, users)
+		if user == nil {
+			sendError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		var req business.UpdateAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+
+		updated, err := accounts.Update(user.ID, req, clientIP(r, trustProxy))
+		if err != nil {
+			setRetryAfter(w, err)
+			status, msg := accountError(err)
+			sendError(w, status, msg)
+			return
+		}
+		json.NewEncoder(w).Encode(updated)
+	}
+}
+
+// DeleteMe удаляет аккаунт; в теле нужен текущий пароль
+func DeleteMe(users repository.UserReader, accounts *services.AccountService, trustProxy bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		user := userFromRequest(r, users)
+		if user == nil {
+			sendError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		var req business.DeleteAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+
+		if err := accounts.Delete(user.ID, req.Password, clientIP(r, trustProxy)); err != nil {
+			setRetryAfter(w, err)
+			status, msg := accountError(err)
+			sendError(w, status, msg)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// accountError переводит ошибку AccountService в статус и текст ответа
+func accountError(err error) (status int, msg string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidAccountUpdate):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, services.ErrWrongPassword):
+		return http.StatusForbidden, err.Error()
+	case errors.As(err, new(*services.LoginLockedError)):
+		return http.StatusTooManyRequests, err.Error()
+	case errors.Is(err, repository.ErrEmailTaken):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, repository.ErrUserNotFound):
+		// Аккаунт удалили параллельным запросом
+		return http.StatusUnauthorized, "Unauthorized"
+	default:
+		return http.StatusInternalServerError, "Failed to update account"
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/repository"
+	"github.com/jaxxiy/myforum/internal/services"
+)
+
+// newAccountTestEnv регистрирует carol с паролем и возвращает ее access-токен.
+// Вход и /api/me ограничены общей защитой: 3 неверных пароля блокируют имя.
+func newAccountTestEnv(t *testing.T) (*testEnv, string) {
+	t.Helper()
+	guard := services.NewLoginGuard(repository.NewMemoryStore(), services.LoginLimits{
+		MaxFailures:      3,
+		MaxFailuresPerIP: 100,
+		Window:           time.Hour,
+		LockoutDuration:  time.Hour,
+	}, log.New(&bytes.Buffer{}, "", 0))
+	env := newGuardedAuthTestEnv(t, guard)
+	users := env.store.Users()
+	RegisterAccountHandlers(env.router, users, services.AccountOptions{
+		Tokens:               users,
+		Mailer:               env.mail,
+		PublicURL:            "http://auth.test",
+		EmailVerificationTTL: time.Hour,
+		Guard:                guard,
+	}, false)
+	if rec := env.authDo(t, "POST", "/auth/register", carol, ""); rec.Code != http.StatusOK {
+		t.Fatalf("register: %d %q", rec.Code, rec.Body.String())
+	}
+	return env, env.login(t, carol).Token
+}
+
+func TestUpdateMe(t *testing.T) {
+	env, token := newAccountTestEnv(t)
+
+	if rec := env.authDo(t, "GET", "/api/me", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("me without token: got %d", rec.Code)
+	}
+	rec := env.authDo(t, "GET", "/api/me", "", token)
+	var me business.User
+	decode(t, rec, &me)
+	if rec.Code != http.StatusOK || me.Username != "carol" || me.Email != "carol@example.com" {
+		t.Fatalf("me: %d %+v", rec.Code, me)
+	}
+
+	for _, tt := range []struct {
+		name string
+		body string
+		want int
+	}{
+		{"profile", `{"bio":"  Пишу на Go  ","avatar_url":"https://example.com/carol.png"}`, http.StatusOK},
+		{"avatar not http", `{"avatar_url":"javascript:alert(1)"}`, http.StatusBadRequest},
+		{"invalid email", `{"email":"carol","current_password":"secret"}`, http.StatusBadRequest},
+		{"email without password", `{"email":"c@example.com"}`, http.StatusForbidden},
+		{"email with wrong password", `{"email":"c@example.com","current_password":"nope"}`, http.StatusForbidden},
+		{"email taken", `{"bio":"не сохранится","email":"alice@example.com","current_password":"secret"}`, http.StatusConflict},
+		{"password without current", `{"new_password":"better"}`, http.StatusForbidden},
+		{"empty password", `{"new_password":"","current_password":"secret"}`, http.StatusBadRequest},
+	} {
+		if rec := env.authDo(t, "PATCH", "/api/me", tt.body, token); rec.Code != tt.want {
+			t.Errorf("%s: got %d %q, want %d", tt.name, rec.Code, rec.Body.String(), tt.want)
+		}
+	}
+
+	// Смена пароля завершает все сессии, включая текущую
+	other := env.login(t, carol)
+	body := `{"email":"c@example.com","new_password":"better","current_password":"secret"}`
+	if rec := env.authDo(t, "PATCH", "/api/me", body, token); rec.Code != http.StatusOK {
+		t.Fatalf("email and password: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := env.authDo(t, "GET", "/api/me", "", token); rec.Code != http.StatusUnauthorized {
+		t.Errorf("me with token issued before password change: got %d", rec.Code)
+	}
+	if rec := env.authDo(t, "POST", "/auth/refresh", fmt.Sprintf(`{"refresh_token":%q}`, other.RefreshToken), ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after password change: got %d", rec.Code)
+	}
+	// Новый адрес нужно подтвердить заново
+	verify := env.mailedToken(t, "c@example.com", "/auth/verify-email")
+
+	// iat хранится с точностью до секунды: токен, выданный в ту же секунду,
+	// что и смена пароля, тоже считается отозванным
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	token = env.login(t, `{"username":"carol","password":"better"}`).Token
+	rec = env.authDo(t, "GET", "/api/me", "", token)
+	decode(t, rec, &me)
+	if me.Bio != "Пишу на Go" || me.AvatarURL != "https://example.com/carol.png" || me.Email != "c@example.com" {
+		t.Errorf("me after update = %+v", me)
+	}
+	if me.EmailVerifiedAt != nil || !me.UpdatedAt.After(me.CreatedAt) {
+		t.Errorf("email_verified_at/updated_at after update = %v/%v", me.EmailVerifiedAt, me.UpdatedAt)
+	}
+	if rec := env.authDo(t, "POST", "/auth/login", carol, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("login with old password: got %d", rec.Code)
+	}
+	if rec := env.authDo(t, "POST", "/auth/verify-email", fmt.Sprintf(`{"token":%q}`, verify), ""); rec.Code != http.StatusNoContent {
+		t.Errorf("verify new email: %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAccountPasswordThrottled(t *testing.T) {
+	env, token := newAccountTestEnv(t)
+
+	for i := 0; i < 3; i++ {
+		if rec := env.authDo(t, "DELETE", "/api/me", `{"password":"nope"}`, token); rec.Code != http.StatusForbidden {
+			t.Fatalf("attempt %d: got %d", i, rec.Code)
+		}
+	}
+	// Имя заблокировано и для /api/me, и для входа, даже с верным паролем
+	rec := env.authDo(t, "DELETE", "/api/me", `{"password":"secret"}`, token)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("delete while locked: %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := env.authDo(t, "PATCH", "/api/me", `{"new_password":"x","current_password":"secret"}`, token); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("update while locked: got %d", rec.Code)
+	}
+	if rec := env.authDo(t, "POST", "/auth/login", carol, ""); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("login while locked: got %d", rec.Code)
+	}
+	if _, err := env.store.Users().GetByUsername("carol"); err != nil {
+		t.Errorf("account deleted while locked: %v", err)
+	}
+}
+
+func TestDeleteMe(t *testing.T) {
+	env, token := newAccountTestEnv(t)
+	path := fmt.Sprintf("/api/forums/%d/messages", env.forumID)
+	if rec := env.authDo(t, "POST", path, `{"content":"до свидания"}`, token); rec.Code != http.StatusCreated {
+		t.Fatalf("post message: %d %q", rec.Code, rec.Body.String())
+	}
+	if !env.canChat(t, token) {
+		t.Fatal("chat message rejected")
+	}
+
+	if rec := env.authDo(t, "DELETE", "/api/me", `{"password":"nope"}`, token); rec.Code != http.StatusForbidden {
+		t.Fatalf("delete with wrong password: got %d", rec.Code)
+	}
+	if rec := env.authDo(t, "DELETE", "/api/me", `{"password":"secret"}`, token); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: %d %q", rec.Code, rec.Body.String())
+	}
+
+	if rec := env.authDo(t, "GET", "/api/me", "", token); rec.Code != http.StatusUnauthorized {
+		t.Errorf("me after delete: got %d", rec.Code)
+	}
+	if rec := env.authDo(t, "POST", "/auth/login", carol, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("login after delete: got %d", rec.Code)
+	}
+
+	// Сообщения остаются, но без имени автора
+	messages, _ := env.store.GetMessages(env.forumID)
+	chat, _ := env.store.GetGlobalMessages(10)
+	authors := map[string]bool{}
+	for _, m := range messages {
+		authors[m.Author] = true
+	}
+	for _, m := range chat {
+		authors[m.Author] = true
+	}
+	if authors["carol"] || !authors[business.DeletedAuthor] {
+		t.Errorf("authors after delete = %v", authors)
+	}
+}
@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
-	"html/template"
+	"errors"
+	"log"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/jaxxiy/myforum/internal/business"
@@ -13,6 +17,9 @@ import (
 
 type AuthHandler struct {
 	authService *services.AuthService
+	// TrustProxy — брать адрес клиента из X-Forwarded-For; включается,
+	// только если сервис стоит за доверенным прокси
+	TrustProxy bool
 }
 
 func NewAuthHandler(authService *services.AuthService) *AuthHandler {
@@ -55,16 +62,230 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := h.authService.Login(req)
+	response, err := h.authService.Login(req, clientIP(r, h.TrustProxy))
+	if setRetryAfter(w, err) {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if errors.Is(err, services.ErrUserBanned) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
 	if err != nil {
+		log.Printf("Ошибка входа: %v", err)
+		http.Error(w, "Failed to log in", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req business.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.authService.Refresh(req.RefreshToken)
+	if errors.Is(err, services.ErrUserBanned) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, services.ErrInvalidRefreshToken) {
 		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
+	if err != nil {
+		http.Error(w, "Failed to refresh token", http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// Logout отзывает refresh-токен из тела и access-токен из заголовка
+// Authorization; оба необязательны
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req business.RefreshRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if err := h.authService.Logout(accessToken, req.RefreshToken); err != nil {
+		http.Error(w, "Failed to log out", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutAll завершает все сессии пользователя, которому выдан access-токен
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, "Authorization header is required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.authService.LogoutAll(strings.TrimPrefix(authHeader, "Bearer ")); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ForgotPassword отправляет ссылку сброса пароля. Ответ один и тот же,
+// есть ли аккаунт с таким email или нет.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req business.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authService.ForgotPassword(req.Email); err != nil {
+		log.Printf("Ошибка отправки ссылки сброса пароля: %v", err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResetPassword задает новый пароль по токену из письма
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req business.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := h.authService.ResetPassword(req.Token, req.Password)
+	if errors.Is(err, services.ErrInvalidOneTimeToken) || errors.Is(err, services.ErrPasswordRequired) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to reset password", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyEmail подтверждает email по токену из письма
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req business.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := h.authService.VerifyEmail(req.Token)
+	if errors.Is(err, services.ErrInvalidOneTimeToken) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to verify email", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResendVerification повторно отправляет письмо подтверждения владельцу токена
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, "Authorization header is required", http.StatusUnauthorized)
+		return
+	}
+
+	err := h.authService.ResendVerification(strings.TrimPrefix(authHeader, "Bearer "))
+	if errors.Is(err, services.ErrEmailAlreadyVerified) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnlockLogin снимает блокировку входа по имени пользователя и/или IP.
+// Нужен access-токен администратора.
+func (h *AuthHandler) UnlockLogin(w http.ResponseWriter, r *http.Request) {
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		http.Error(w, "Authorization header is required", http.StatusUnauthorized)
+		return
+	}
+	admin, err := h.authService.ValidateToken(strings.TrimPrefix(authHeader, "Bearer "))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var req business.UnlockLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.authService.UnlockLogin(admin, req)
+	if errors.Is(err, services.ErrPermissionDenied) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, services.ErrNothingToUnlock) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Ошибка снятия блокировки входа: %v", err)
+		http.Error(w, "Failed to unlock", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setRetryAfter ставит заголовок Retry-After, если err — *services.LoginLockedError
+func setRetryAfter(w http.ResponseWriter, err error) bool {
+	var locked *services.LoginLockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+	// В целых секундах, с округлением вверх
+	w.Header().Set("Retry-After", strconv.Itoa(int((locked.RetryAfter+time.Second-1)/time.Second)))
+	return true
+}
+
+// clientIP — адрес клиента. За прокси это последний адрес из X-Forwarded-For:
+// его дописал сам прокси, более ранние мог подставить клиент.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			hops := strings.Split(values[len(values)-1], ",")
+			if ip := strings.TrimSpace(hops[len(hops)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func (h *AuthHandler) ValidateToken(w http.ResponseWriter, r *http.Request) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
@@ -90,13 +311,19 @@ func (h *AuthHandler) ValidateToken(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(user)
 }
 
+// JWKS отдает открытые ключи проверки токенов; форум кеширует их
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(h.authService.JWKS())
+}
+
 func (h *AuthHandler) RegisterPage(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	templates := template.Must(template.ParseGlob("C:/Users/Soulless/Desktop/myforum/templates/*.html"))
-	templates.ExecuteTemplate(w, "register.html", nil)
+	renderTemplate(w, "register.html", nil)
 }
 
 func (h *AuthHandler) LoginPage(w http.ResponseWriter, r *http.Request) {
@@ -104,12 +331,34 @@ func (h *AuthHandler) LoginPage(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	templates := template.Must(template.ParseGlob("C:/Users/Soulless/Desktop/myforum/templates/*.html"))
-	templates.ExecuteTemplate(w, "login.html", nil)
+	renderTemplate(w, "login.html", nil)
+}
+
+// ForgotPasswordPage, ResetPasswordPage и VerifyEmailPage — страницы, на которые
+// ведут ссылки из писем; токен они берут из параметра token
+func (h *AuthHandler) ForgotPasswordPage(w http.ResponseWriter, r *http.Request) {
+	renderTemplate(w, "forgot_password.html", nil)
+}
+
+func (h *AuthHandler) ResetPasswordPage(w http.ResponseWriter, r *http.Request) {
+	renderTemplate(w, "reset_password.html", nil)
+}
+
+func (h *AuthHandler) VerifyEmailPage(w http.ResponseWriter, r *http.Request) {
+	renderTemplate(w, "verify_email.html", nil)
 }
 
 func RegisterAuthRoutes(r *mux.Router, authHandler *AuthHandler) {
 	auth := r.PathPrefix("/auth").Subrouter()
 	auth.HandleFunc("/register", authHandler.Register).Methods("POST")
 	auth.HandleFunc("/login", authHandler.Login).Methods("POST")
+	auth.HandleFunc("/refresh", authHandler.Refresh).Methods("POST")
+	auth.HandleFunc("/logout", authHandler.Logout).Methods("POST")
+	auth.HandleFunc("/logout-all", authHandler.LogoutAll).Methods("POST")
+	auth.HandleFunc("/forgot-password", authHandler.ForgotPassword).Methods("POST")
+	auth.HandleFunc("/reset-password", authHandler.ResetPassword).Methods("POST")
+	auth.HandleFunc("/verify-email", authHandler.VerifyEmail).Methods("POST")
+	auth.HandleFunc("/verify-email/resend", authHandler.ResendVerification).Methods("POST")
+	auth.HandleFunc("/admin/unlock", authHandler.UnlockLogin).Methods("POST")
+	r.HandleFunc("/.well-known/jwks.json", authHandler.JWKS).Methods("GET")
 }
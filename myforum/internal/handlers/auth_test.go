@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/mail"
+	"github.com/jaxxiy/myforum/internal/repository"
+	"github.com/jaxxiy/myforum/internal/services"
+)
+
+// newAuthTestEnv подключает к тестовому окружению сервис авторизации с той же
+// памятью, чтобы форум видел отозванные им токены
+func newAuthTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	return newGuardedAuthTestEnv(t, nil)
+}
+
+// newGuardedAuthTestEnv — то же, но с защитой входа от перебора
+func newGuardedAuthTestEnv(t *testing.T, guard *services.LoginGuard) *testEnv {
+	t.Helper()
+	env := newTestEnv(t)
+	env.mail = mail.NewMemory()
+	users := env.store.Users()
+	auth := services.NewAuthService(users, users, testTokens, services.AuthOptions{
+		AccessTTL:            time.Hour,
+		RefreshTTL:           24 * time.Hour,
+		PasswordResetTTL:     time.Hour,
+		EmailVerificationTTL: time.Hour,
+		Mailer:               env.mail,
+		PublicURL:            "http://auth.test",
+		Guard:                guard,
+	})
+	RegisterAuthRoutes(env.router, NewAuthHandler(auth))
+	return env
+}
+
+// authDo выполняет запрос с готовым access-токеном вместо ID пользователя
+func (e *testEnv) authDo(t *testing.T, method, path, body, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	e.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func (e *testEnv) login(t *testing.T, body string) business.AuthResponse {
+	t.Helper()
+	rec := e.authDo(t, "POST", "/auth/login", body, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login: %d %q", rec.Code, rec.Body.String())
+	}
+	var resp business.AuthResponse
+	decode(t, rec, &resp)
+	if resp.Token == "" || resp.RefreshToken == "" || resp.ExpiresIn != 3600 {
+		t.Fatalf("login response = %+v", resp)
+	}
+	return resp
+}
+
+// canChat проверяет access-токен на защищенном методе форума
+func (e *testEnv) canChat(t *testing.T, token string) bool {
+	t.Helper()
+	return e.authDo(t, "POST", "/api/global-chat", `{"text":"hi"}`, token).Code == http.StatusCreated
+}
+
+const carol = `{"username":"carol","email":"carol@example.com","password":"secret"}`
+
+func TestRefreshToken(t *testing.T) {
+	env := newAuthTestEnv(t)
+	if rec := env.authDo(t, "POST", "/auth/register", carol, ""); rec.Code != http.StatusOK {
+		t.Fatalf("register: %d %q", rec.Code, rec.Body.String())
+	}
+	first := env.login(t, carol)
+	if !env.canChat(t, first.Token) {
+		t.Fatal("fresh access token rejected")
+	}
+
+	rec := env.authDo(t, "POST", "/auth/refresh", `{"refresh_token":"`+first.RefreshToken+`"}`, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("refresh: %d %q", rec.Code, rec.Body.String())
+	}
+	var second business.AuthResponse
+	decode(t, rec, &second)
+	if second.RefreshToken == first.RefreshToken || second.User.Username != "carol" || !env.canChat(t, second.Token) {
+		t.Fatalf("refresh response = %+v", second)
+	}
+
+	// Повторный обмен уже использованного токена завершает все сессии
+	if rec := env.authDo(t, "POST", "/auth/refresh", `{"refresh_token":"`+first.RefreshToken+`"}`, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("reused refresh token: got %d", rec.Code)
+	}
+	if rec := env.authDo(t, "POST", "/auth/refresh", `{"refresh_token":"`+second.RefreshToken+`"}`, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh after reuse: got %d", rec.Code)
+	}
+	if rec := env.authDo(t, "POST", "/auth/refresh", `{"refresh_token":"bogus"}`, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("unknown refresh token: got %d", rec.Code)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	env := newAuthTestEnv(t)
+	env.authDo(t, "POST", "/auth/register", carol, "")
+	session := env.login(t, carol)
+	other := env.login(t, carol)
+
+	rec := env.authDo(t, "POST", "/auth/logout", `{"refresh_token":"`+session.RefreshToken+`"}`, session.Token)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("logout: %d %q", rec.Code, rec.Body.String())
+	}
+	if env.canChat(t, session.Token) {
+		t.Fatal("access token works after logout")
+	}
+	if rec := env.authDo(t, "POST", "/auth/refresh", `{"refresh_token":"`+session.RefreshToken+`"}`, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh after logout: got %d", rec.Code)
+	}
+	// Выход из одной сессии не трогает другую
+	if !env.canChat(t, other.Token) {
+		t.Fatal("other session was logged out")
+	}
+	if rec := env.authDo(t, "POST", "/auth/logout", "", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("logout without tokens: got %d", rec.Code)
+	}
+}
+
+func TestLogoutAll(t *testing.T) {
+	env := newAuthTestEnv(t)
+	env.authDo(t, "POST", "/auth/register", carol, "")
+	session := env.login(t, carol)
+	other := env.login(t, carol)
+
+	if rec := env.authDo(t, "POST", "/auth/logout-all", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("logout-all without token: got %d", rec.Code)
+	}
+	if rec := env.authDo(t, "POST", "/auth/logout-all", "", session.Token); rec.Code != http.StatusNoContent {
+		t.Fatalf("logout-all: %d %q", rec.Code, rec.Body.String())
+	}
+	if env.canChat(t, session.Token) {
+		t.Fatal("access token works after logout-all")
+	}
+	for _, refresh := range []string{session.RefreshToken, other.RefreshToken} {
+		if rec := env.authDo(t, "POST", "/auth/refresh", `{"refresh_token":"`+refresh+`"}`, ""); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("refresh after logout-all: got %d", rec.Code)
+		}
+	}
+
+	// Новый вход после выхода со всех устройств работает
+	if !env.canChat(t, env.login(t, carol).Token) {
+		t.Fatal("new session rejected after logout-all")
+	}
+}
+
+// mailedToken достает токен из ссылки в последнем письме на адрес to
+func (e *testEnv) mailedToken(t *testing.T, to, path string) string {
+	t.Helper()
+	msg, ok := e.mail.Last(to)
+	if !ok {
+		t.Fatalf("no mail to %s", to)
+	}
+	prefix := "http://auth.test" + path + "?token="
+	i := strings.Index(msg.Body, prefix)
+	if i < 0 {
+		t.Fatalf("mail to %s has no %s link:\n%s", to, path, msg.Body)
+	}
+	token, _, _ := strings.Cut(msg.Body[i+len(prefix):], "\n")
+	token, err := url.QueryUnescape(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestPasswordReset(t *testing.T) {
+	env := newAuthTestEnv(t)
+	env.authDo(t, "POST", "/auth/register", carol, "")
+	session := env.login(t, carol)
+
+	// Ответ на неизвестный адрес такой же, но письма нет
+	if rec := env.authDo(t, "POST", "/auth/forgot-password", `{"email":"nobody@example.com"}`, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("forgot unknown email: got %d", rec.Code)
+	}
+	if _, ok := env.mail.Last("nobody@example.com"); ok {
+		t.Fatal("mail sent to unknown address")
+	}
+
+	if rec := env.authDo(t, "POST", "/auth/forgot-password", `{"email":"carol@example.com"}`, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("forgot password: %d %q", rec.Code, rec.Body.String())
+	}
+	stale := env.mailedToken(t, "carol@example.com", "/auth/reset-password")
+	env.authDo(t, "POST", "/auth/forgot-password", `{"email":"carol@example.com"}`, "")
+	token := env.mailedToken(t, "carol@example.com", "/auth/reset-password")
+
+	reset := func(token, password string) int {
+		return env.authDo(t, "POST", "/auth/reset-password", `{"token":"`+token+`","password":"`+password+`"}`, "").Code
+	}
+	if code := reset(stale, "stolen"); code != http.StatusBadRequest {
+		t.Fatalf("superseded reset link: got %d", code)
+	}
+	if code := reset(token, ""); code != http.StatusBadRequest {
+		t.Fatalf("empty password: got %d", code)
+	}
+	if code := reset(token, "new-secret"); code != http.StatusNoContent {
+		t.Fatalf("reset password: got %d", code)
+	}
+	if code := reset(token, "again"); code != http.StatusBadRequest {
+		t.Fatalf("reused reset link: got %d", code)
+	}
+
+	// Старый пароль и прежние сессии больше не действуют
+	if rec := env.authDo(t, "POST", "/auth/login", carol, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("login with old password: got %d", rec.Code)
+	}
+	if env.canChat(t, session.Token) {
+		t.Fatal("access token works after password reset")
+	}
+	env.login(t, `{"username":"carol","password":"new-secret"}`)
+}
+
+func TestVerifyEmail(t *testing.T) {
+	env := newAuthTestEnv(t)
+	env.authDo(t, "POST", "/auth/register", carol, "")
+	first := env.mailedToken(t, "carol@example.com", "/auth/verify-email")
+	session := env.login(t, carol)
+	if session.User.EmailVerifiedAt != nil {
+		t.Fatal("email verified before following the link")
+	}
+
+	// Повторное письмо заменяет прежнюю ссылку
+	if rec := env.authDo(t, "POST", "/auth/verify-email/resend", "", session.Token); rec.Code != http.StatusNoContent {
+		t.Fatalf("resend: %d %q", rec.Code, rec.Body.String())
+	}
+	token := env.mailedToken(t, "carol@example.com", "/auth/verify-email")
+	verify := func(token string) int {
+		return env.authDo(t, "POST", "/auth/verify-email", `{"token":"`+token+`"}`, "").Code
+	}
+	if code := verify(first); code != http.StatusBadRequest {
+		t.Fatalf("superseded verification link: got %d", code)
+	}
+	if code := verify(token); code != http.StatusNoContent {
+		t.Fatalf("verify email: got %d", code)
+	}
+	if code := verify(token); code != http.StatusBadRequest {
+		t.Fatalf("reused verification link: got %d", code)
+	}
+
+	if user := env.login(t, carol).User; user.EmailVerifiedAt == nil {
+		t.Fatal("email not verified")
+	}
+	if rec := env.authDo(t, "POST", "/auth/verify-email/resend", "", session.Token); rec.Code != http.StatusConflict {
+		t.Fatalf("resend after verification: got %d", rec.Code)
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	var audit bytes.Buffer
+	store := repository.NewMemoryStore()
+	env := newGuardedAuthTestEnv(t, services.NewLoginGuard(store, services.LoginLimits{
+		MaxFailures:      3,
+		MaxFailuresPerIP: 10,
+		Window:           time.Hour,
+		LockoutDuration:  time.Hour,
+	}, log.New(&audit, "", 0)))
+	env.authDo(t, "POST", "/auth/register", carol, "")
+
+	wrong := `{"username":"Carol","password":"wrong"}`
+	for i := 0; i < 3; i++ {
+		if rec := env.authDo(t, "POST", "/auth/login", wrong, ""); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: %d %q", i, rec.Code, rec.Body.String())
+		}
+	}
+	// Даже верный пароль не принимается, пока вход заблокирован
+	rec := env.authDo(t, "POST", "/auth/login", carol, "")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("locked login: %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if !strings.Contains(audit.String(), `login locked: username="carol"`) {
+		t.Errorf("audit = %q", audit.String())
+	}
+
+	unlock := `{"username":"carol"}`
+	if rec := env.authDo(t, "POST", "/auth/admin/unlock", unlock, tokenFor(t, env.alice)); rec.Code != http.StatusForbidden {
+		t.Fatalf("unlock by user: %d", rec.Code)
+	}
+	if rec := env.authDo(t, "POST", "/auth/admin/unlock", `{}`, tokenFor(t, env.admin)); rec.Code != http.StatusBadRequest {
+		t.Fatalf("empty unlock: %d", rec.Code)
+	}
+	if rec := env.authDo(t, "POST", "/auth/admin/unlock", unlock, tokenFor(t, env.admin)); rec.Code != http.StatusNoContent {
+		t.Fatalf("unlock: %d %q", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(audit.String(), `login unlocked: username="carol" by root`) {
+		t.Errorf("audit = %q", audit.String())
+	}
+	env.login(t, carol)
+}
+
+func TestLoginDelay(t *testing.T) {
+	env := newGuardedAuthTestEnv(t, services.NewLoginGuard(repository.NewMemoryStore(), services.LoginLimits{
+		Window:          time.Hour,
+		Delay:           time.Hour,
+		LockoutDuration: 2 * time.Hour,
+	}, log.New(&bytes.Buffer{}, "", 0)))
+	env.authDo(t, "POST", "/auth/register", carol, "")
+	dave := `{"username":"dave","email":"dave@example.com","password":"secret"}`
+	env.authDo(t, "POST", "/auth/register", dave, "")
+
+	env.authDo(t, "POST", "/auth/login", `{"username":"carol","password":"wrong"}`, "")
+	rec := env.authDo(t, "POST", "/auth/login", carol, "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("login during delay: %d", rec.Code)
+	}
+	if after := rec.Header().Get("Retry-After"); after != "3600" && after != "3599" {
+		t.Errorf("Retry-After = %q", after)
+	}
+	// Пауза по имени не мешает другим пользователям с того же адреса
+	env.login(t, dave)
+}
+
+func TestLoginLimitPerIP(t *testing.T) {
+	env := newGuardedAuthTestEnv(t, services.NewLoginGuard(repository.NewMemoryStore(), services.LoginLimits{
+		MaxFailures:      10,
+		MaxFailuresPerIP: 3,
+		Window:           time.Hour,
+		LockoutDuration:  time.Hour,
+	}, log.New(&bytes.Buffer{}, "", 0)))
+	env.authDo(t, "POST", "/auth/register", carol, "")
+
+	for i := 0; i < 3; i++ {
+		body := fmt.Sprintf(`{"username":"guess%d","password":"wrong"}`, i)
+		env.authDo(t, "POST", "/auth/login", body, "")
+	}
+	if rec := env.authDo(t, "POST", "/auth/login", carol, ""); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("login from locked ip: %d", rec.Code)
+	}
+	if rec := env.authDo(t, "POST", "/auth/admin/unlock", `{"ip":"192.0.2.1"}`, tokenFor(t, env.admin)); rec.Code != http.StatusNoContent {
+		t.Fatalf("unlock ip: %d", rec.Code)
+	}
+	env.login(t, carol)
+}
@@ -2,7 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"html/template"
 	"log"
 	"net/http"
@@ -12,40 +12,47 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/gorilla/websocket"
 	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/events"
+	"github.com/jaxxiy/myforum/internal/rbac"
 	"github.com/jaxxiy/myforum/internal/repository"
+	"github.com/jaxxiy/myforum/internal/ws"
 	"github.com/jaxxiy/myforum/pkg/jwt"
 )
 
+// TemplatesPattern — шаблоны HTML-страниц; они загружаются при первом рендере,
+// поэтому путь можно поменять до запуска сервера
+var TemplatesPattern = "templates/*.html"
+
 var (
-	templates = template.Must(template.ParseGlob("C:/Users/Soulless/Desktop/myforum/templates/*.html"))
-	upgrader  = websocket.Upgrader{
-		ReadBufferSize:  1024,
-		WriteBufferSize: 1024,
-		CheckOrigin: func(r *http.Request) bool {
-			return true // Для разработки
-		},
-	}
-	clients   = make(map[int]map[*websocket.Conn]bool) // forumID -> connections
-	clientsMu sync.RWMutex
-
-	//mini-chat
-	globalChatClients  = make(map[*websocket.Conn]bool)
-	globalChatMu       sync.RWMutex
-	globalChatUpgrader = websocket.Upgrader{
-		ReadBufferSize:  1024,
-		WriteBufferSize: 1024,
-		CheckOrigin: func(r *http.Request) bool {
-			return true // Для разработки
-		},
-	}
-	globalChatBroadcast = make(chan GlobalChatMessage)
-	globalChatHistory   []GlobalChatMessage
+	templates     *template.Template
+	templatesOnce sync.Once
+
+	// WebSocket-хаб: комнаты форумов и мини-чата
+	hub *ws.Hub
+
+	// Шина событий форумов для gRPC-подписчиков (SubscribeForum)
+	eventBus *events.Bus
+
+	// Проверка JWT в заголовке Authorization
+	tokenManager *jwt.Manager
+
+	// Список отозванных токенов (выход из сессии)
+	revocations repository.RevocationChecker
+
+	// Разрешены ли WebSocket-соединения без токена (только чтение)
+	allowAnonymousWS bool
 )
 
+// Options — настройки обработчиков форума
+type Options struct {
+	Tokens *jwt.Manager
+	// AllowAnonymousWS пускает к /ws/global и /ws/{forum_id} без токена в режиме только для чтения
+	AllowAnonymousWS bool
+}
+
+// GlobalChatMessageRequest — сообщение мини-чата; автор берется из токена
 type GlobalChatMessageRequest struct {
-	Author  string `json:"username"`
 	Content string `json:"text"`
 }
 
@@ -55,21 +62,31 @@ type GlobalChatMessage struct {
 	CreatedAt time.Time `json:"timestamp"`
 }
 
+// Глубина дерева ответов для /messages/tree
+const (
+	defaultReplyDepth = 5
+	maxReplyDepth     = 10
+)
+
 type WSMessage struct {
 	Type    string      `json:"type"`
 	Payload interface{} `json:"payload"`
 }
 
-func RegisterForumHandlers(r *mux.Router, repo *repository.ForumsRepo) {
+func RegisterForumHandlers(r *mux.Router, repo repository.Store, topics repository.TopicStore, bus *events.Bus, h *ws.Hub, opts Options) {
+	eventBus = bus
+	hub = h
+	tokenManager = opts.Tokens
+	revocations = repo
+	allowAnonymousWS = opts.AllowAnonymousWS
 
 	r.HandleFunc("/ws/global", func(w http.ResponseWriter, r *http.Request) {
 		serveGlobalChat(w, r, repo)
 	})
 
 	r.HandleFunc("/ws/{forum_id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
-		serveWebSocket(w, r)
+		serveWebSocket(w, r, repo)
 	})
-	go handleGlobalChatMessages()
 
 	api := r.PathPrefix("/api").Subrouter()
 
@@ -79,17 +96,31 @@ func RegisterForumHandlers(r *mux.Router, repo *repository.ForumsRepo) {
 	api.HandleFunc("/forums", ListForums(repo)).Methods("GET")
 	api.HandleFunc("/forums/new", NewForumForm()).Methods("GET")
 	api.HandleFunc("/forums", CreateForum(repo)).Methods("POST")
-	api.HandleFunc("/forums/{id:[0-9]+}", GetForum(repo)).Methods("GET")
+	api.HandleFunc("/forums/{id:[0-9]+}", GetForum(repo, topics)).Methods("GET")
 	api.HandleFunc("/forums/{id:[0-9]+}", UpdateForum(repo)).Methods("PUT")
 	api.HandleFunc("/forums/{id:[0-9]+}", DeleteForum(repo)).Methods("DELETE")
 
 	// Обработчики сообщений
 	api.HandleFunc("/forums/{id:[0-9]+}/messages", GetMessages(repo)).Methods("GET")
 	api.HandleFunc("/forums/{id:[0-9]+}/messages", PostMessage(repo)).Methods("POST")
+	api.HandleFunc("/forums/{id:[0-9]+}/messages/tree", GetMessageTree(repo)).Methods("GET")
 	api.HandleFunc("/forums/{forum_id:[0-9]+}/messages/{message_id:[0-9]+}", DeleteMessage(repo)).Methods("DELETE")
 	api.HandleFunc("/forums/{id:[0-9]+}/messages/{message_id:[0-9]+}", UpdateMessage(repo)).Methods("PUT")
+	api.HandleFunc("/forums/{id:[0-9]+}/messages/{message_id:[0-9]+}/revisions", GetMessageRevisions(repo)).Methods("GET")
+
+	// Темы форума
+	registerTopicHandlers(api, repo, topics)
+
+	// Корзина администратора
+	registerTrashHandlers(r, api, repo)
+
+	// Роли пользователей и модераторы форумов
+	registerRoleHandlers(api, repo)
+	registerModeratorHandlers(api, repo)
+	registerProfileHandlers(r, api, repo)
 
 	api.HandleFunc("/global-chat", handleGlobalChatMessage(repo)).Methods("POST")
+	api.HandleFunc("/global-chat", GetGlobalChatHistory(repo)).Methods("GET")
 
 	// Новый API-эндпоинт для загрузки сообщений с учетом токена
 	api.HandleFunc("/forums/{id:[0-9]+}/messages-list", GetMessagesAPI(repo)).Methods("GET")
@@ -97,100 +128,39 @@ func RegisterForumHandlers(r *mux.Router, repo *repository.ForumsRepo) {
 }
 
 func LoginPage(w http.ResponseWriter, r *http.Request) {
-	templates.ExecuteTemplate(w, "login.html", nil)
+	loadTemplates().ExecuteTemplate(w, "login.html", nil)
 }
 
 func RegisterPage(w http.ResponseWriter, r *http.Request) {
-	templates.ExecuteTemplate(w, "register.html", nil)
+	loadTemplates().ExecuteTemplate(w, "register.html", nil)
 }
 
-// Улучшенный обработчик WebSocket
-func serveWebSocket(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	forumID, err := strconv.Atoi(vars["forum_id"])
+// serveWebSocket подписывает соединение на события форума; входящие сообщения не ожидаются
+func serveWebSocket(w http.ResponseWriter, r *http.Request, repo repository.Store) {
+	forumID, err := strconv.Atoi(mux.Vars(r)["forum_id"])
 	if err != nil {
 		http.Error(w, "Invalid forum ID", http.StatusBadRequest)
 		return
 	}
-
-	conn, err := upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Printf("WebSocket upgrade error: %v", err)
+	user, ok := authenticateWS(w, r, repo)
+	if !ok {
 		return
 	}
-	defer func() {
-		unregisterClient(forumID, conn)
-		conn.Close()
-	}()
-
-	registerClient(forumID, conn)
-
-	// Настройка keep-alive
-	conn.SetPongHandler(func(string) error {
-		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-		return nil
-	})
-
-	// Чтение сообщений (для поддержания соединения)
-	for {
-		_, _, err := conn.ReadMessage()
-		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
-				log.Printf("WebSocket error: %v", err)
-			}
-			break
-		}
+	if _, status, errMsg := forumForRead(repo, forumID, user); status != 0 {
+		http.Error(w, errMsg, status)
+		return
 	}
+
+	hub.Serve(w, r, ws.ForumRoom(forumID), ws.Handlers{})
 }
 
 // Отправка сообщения всем клиентам форума
 func broadcastToForum(forumID int, message WSMessage) {
-	clientsMu.RLock()
-	defer clientsMu.RUnlock()
-
-	if conns, ok := clients[forumID]; ok {
-		for conn := range conns {
-			if err := conn.WriteJSON(message); err != nil {
-				log.Printf("WS send error: %v", err)
-				go handleFailedConnection(forumID, conn)
-			}
-		}
-	}
-}
-
-func handleFailedConnection(forumID int, conn *websocket.Conn) {
-	clientsMu.Lock()
-	defer clientsMu.Unlock()
-
-	if conns, ok := clients[forumID]; ok {
-		conn.Close()
-		delete(conns, conn)
-		log.Printf("Connection removed for forum %d", forumID)
-	}
-}
-
-func registerClient(forumID int, conn *websocket.Conn) {
-	clientsMu.Lock()
-	defer clientsMu.Unlock()
-
-	if clients[forumID] == nil {
-		clients[forumID] = make(map[*websocket.Conn]bool)
-	}
-	clients[forumID][conn] = true
-	log.Printf("New client for forum %d. Total: %d", forumID, len(clients[forumID]))
-}
-
-func unregisterClient(forumID int, conn *websocket.Conn) {
-	clientsMu.Lock()
-	defer clientsMu.Unlock()
-
-	if clients[forumID] != nil {
-		delete(clients[forumID], conn)
-	}
+	hub.PublishJSON(ws.ForumRoom(forumID), message)
 }
 
 // Улучшенный обработчик сообщений
-func PostMessage(repo *repository.ForumsRepo) http.HandlerFunc {
+func PostMessage(repo repository.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
@@ -210,8 +180,10 @@ func PostMessage(repo *repository.ForumsRepo) http.HandlerFunc {
 
 		// Декодируем JSON
 		var req struct {
-			Author  string `json:"author"`
-			Content string `json:"content"`
+			AuthorID int    `json:"author_id"` // 0 — автор из токена
+			Content  string `json:"content"`
+			ParentID int    `json:"parent_id"`
+			QuoteID  int    `json:"quote_id"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			sendError(w, http.StatusBadRequest, "Invalid JSON format")
@@ -219,42 +191,57 @@ func PostMessage(repo *repository.ForumsRepo) http.HandlerFunc {
 		}
 
 		// Валидация
-		if strings.TrimSpace(req.Author) == "" || strings.TrimSpace(req.Content) == "" {
-			sendError(w, http.StatusBadRequest, "Author and content are required")
+		if strings.TrimSpace(req.Content) == "" {
+			sendError(w, http.StatusBadRequest, "Content is required")
 			return
 		}
 
 		// Получаем пользователя из токена
-		authHeader := r.Header.Get("Authorization")
-		var user *business.User
-		if authHeader != "" {
-			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-			if claims, err := jwt.ParseToken(tokenString, "your-secret-key"); err == nil {
-				if u, err := repo.GetUserByID(claims.UserID); err == nil {
-					user = u
-				}
-			}
-		}
+		user := userFromRequest(r, repo)
 		if user == nil {
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
-		// Проверяем права: автор или admin
-		if user.Username != req.Author && user.Role != "admin" {
+		// Автор берется из токена; от имени другого пользователя (author_id)
+		// может писать только тот, у кого есть message.create.any
+		author := user
+		if req.AuthorID != 0 && req.AuthorID != user.ID {
+			if !rbac.CanOwned(user, rbac.MessageCreate, req.AuthorID) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			author, err = repo.GetUserByID(req.AuthorID)
+			if errors.Is(err, repository.ErrUserNotFound) {
+				sendError(w, http.StatusBadRequest, "Unknown author")
+				return
+			}
+			if err != nil {
+				sendError(w, http.StatusInternalServerError, "Failed to load author")
+				return
+			}
+		} else if !rbac.CanOwned(user, rbac.MessageCreate, user.ID) {
 			http.Error(w, "Forbidden", http.StatusForbidden)
 			return
 		}
+		if _, status, errMsg := forumForWrite(repo, forumID, user); status != 0 {
+			sendError(w, status, errMsg)
+			return
+		}
 
 		// Создаем сообщение
 		msg := business.Message{
 			ForumID:   forumID,
-			Author:    req.Author,
+			AuthorID:  author.ID,
+			Author:    author.Username,
 			Content:   req.Content,
 			CreatedAt: time.Now(),
 		}
 
-		fmt.Println(msg.CreatedAt)
+		if status, errMsg := resolveReplyAndQuote(repo, &msg, req.ParentID, req.QuoteID); status != 0 {
+			sendError(w, status, errMsg)
+			return
+		}
 
 		// Сохраняем в БД
 		id, err := repo.CreateMessage(msg)
@@ -266,10 +253,11 @@ func PostMessage(repo *repository.ForumsRepo) http.HandlerFunc {
 		msg.ID = id
 
 		// Отправляем через WebSocket
-		go broadcastToForum(forumID, WSMessage{
-			Type:    "message_created",
+		broadcastToForum(forumID, WSMessage{
+			Type:    events.MessageCreated,
 			Payload: msg,
 		})
+		eventBus.Publish(events.Event{Type: events.MessageCreated, ForumID: forumID, Message: &msg})
 
 		// Успешный ответ
 		w.WriteHeader(http.StatusCreated)
@@ -277,35 +265,66 @@ func PostMessage(repo *repository.ForumsRepo) http.HandlerFunc {
 	}
 }
 
+// resolveReplyAndQuote проверяет parent_id и quote_id и заполняет в сообщении
+// родителя и снимок цитаты. Ответ попадает в тему родителя. При ошибке
+// возвращает HTTP-статус и текст для клиента.
+func resolveReplyAndQuote(repo repository.Store, msg *business.Message, parentID, quoteID int) (int, string) {
+	if parentID != 0 {
+		parent, err := repo.GetMessageByID(parentID)
+		if err != nil || parent.ForumID != msg.ForumID {
+			return http.StatusBadRequest, "Parent message not found in this forum"
+		}
+		if msg.TopicID != 0 && parent.TopicID != msg.TopicID {
+			return http.StatusBadRequest, "Parent message belongs to another topic"
+		}
+		msg.ParentID = parent.ID
+		msg.TopicID = parent.TopicID
+	}
+
+	if quoteID != 0 {
+		quoted, err := repo.GetMessageByID(quoteID)
+		if err != nil || quoted.ForumID != msg.ForumID {
+			return http.StatusBadRequest, "Quoted message not found in this forum"
+		}
+		msg.Quote = &business.MessageQuote{
+			MessageID: quoted.ID,
+			Author:    quoted.Author,
+			Content:   quoted.Content,
+		}
+	}
+	return 0, ""
+}
+
 func sendError(w http.ResponseWriter, status int, message string) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
 
-// sendWSMessage отправляет сообщение всем клиентам в указанном форуме
-func sendWSMessage(forumID int, message WSMessage) {
-	clientsMu.RLock()
-	defer clientsMu.RUnlock()
-
-	if conns, ok := clients[forumID]; ok {
-		for conn := range conns {
-			if err := conn.WriteJSON(message); err != nil {
-				log.Printf("WebSocket send error: %v", err)
-				go handleFailedConnection(forumID, conn)
-			}
+func ListForums(repo repository.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, err := parsePageRequest(r, false)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
-	}
-}
 
-func ListForums(repo *repository.ForumsRepo) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		forums, err := repo.GetAll()
+		forums, hasMore, err := repo.GetAllPage(page)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+
+		var first, last *repository.Cursor
+		if len(forums) > 0 {
+			first = &repository.Cursor{CreatedAt: forums[0].CreatedAt, ID: forums[0].ID}
+			last = &repository.Cursor{CreatedAt: forums[len(forums)-1].CreatedAt, ID: forums[len(forums)-1].ID}
+		}
+		next, prev := pageLinks(r, page, first, last, hasMore)
+
 		renderTemplate(w, "list_forums.html", map[string]interface{}{
 			"Forums": forums,
+			"Next":   next,
+			"Prev":   prev,
 		})
 	}
 }
@@ -317,14 +336,39 @@ func NewForumForm() http.HandlerFunc {
 }
 
 // Обработчик для создания форума
-func CreateForum(repo *repository.ForumsRepo) http.HandlerFunc {
+func CreateForum(repo repository.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		user := userFromRequest(r, repo)
+		if user == nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !rbac.Can(user, rbac.ForumCreate) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
 		title := r.FormValue("title")
 		description := r.FormValue("description")
+		if strings.TrimSpace(title) == "" {
+			http.Error(w, "Title is required", http.StatusBadRequest)
+			return
+		}
+		visibility := r.FormValue("visibility")
+		if visibility == "" {
+			visibility = business.VisibilityPublic
+		}
+		if !business.ValidVisibility(visibility) {
+			http.Error(w, "Invalid visibility", http.StatusBadRequest)
+			return
+		}
 
+		// Создатель становится владельцем форума
 		forum := business.Forum{
 			Title:       title,
 			Description: description,
+			OwnerID:     user.ID,
+			Visibility:  visibility,
 			CreatedAt:   time.Now(),
 		}
 
@@ -336,7 +380,7 @@ func CreateForum(repo *repository.ForumsRepo) http.HandlerFunc {
 		forum.ID = id
 
 		// Отправляем уведомление через WebSocket
-		sendWSMessage(id, WSMessage{
+		broadcastToForum(id, WSMessage{
 			Type: "forum_created",
 			Payload: map[string]interface{}{
 				"forum": forum,
@@ -351,8 +395,8 @@ func CreateForum(repo *repository.ForumsRepo) http.HandlerFunc {
 	}
 }
 
-// Обработчик для получения форума по ID
-func GetForum(repo *repository.ForumsRepo) http.HandlerFunc {
+// Обработчик для получения форума по ID вместе со списком тем
+func GetForum(repo repository.Store, topics repository.TopicStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		idStr := vars["id"]
@@ -367,12 +411,25 @@ func GetForum(repo *repository.ForumsRepo) http.HandlerFunc {
 			return
 		}
 
-		renderTemplate(w, "forum_detail.html", f)
+		// Темы форума для участников страница показывает только с токеном
+		var forumTopics []business.Topic
+		if rbac.CanReadForum(userFromRequest(r, repo), f) {
+			forumTopics, err = topics.GetByForum(id)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		renderTemplate(w, "forum_detail.html", map[string]interface{}{
+			"Forum":  f,
+			"Topics": forumTopics,
+		})
 	}
 }
 
 // GetAllForums возвращает все форумы
-func GetAllForums(repo *repository.ForumsRepo) http.HandlerFunc {
+func GetAllForums(repo repository.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		forums, err := repo.GetAll()
 		if err != nil {
@@ -383,42 +440,112 @@ func GetAllForums(repo *repository.ForumsRepo) http.HandlerFunc {
 	}
 }
 
-func UpdateForum(repo *repository.ForumsRepo) http.HandlerFunc {
+func UpdateForum(repo repository.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		id, _ := strconv.Atoi(vars["id"])
 
-		var forum business.Forum
-		if err := json.NewDecoder(r.Body).Decode(&forum); err != nil {
+		user := userFromRequest(r, repo)
+		if user == nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		stored, err := repo.GetByID(id)
+		if err != nil {
+			http.Error(w, "Forum not found", http.StatusNotFound)
+			return
+		}
+		// Настройки форума меняют его владелец, модераторы и глобальные модераторы
+		if !rbac.CanInForum(user, rbac.ForumUpdate, forumAccess(repo, stored, user)) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		// Меняются только поля из тела запроса, как в PATCH
+		var req struct {
+			Title       *string `json:"title"`
+			Description *string `json:"description"`
+			Visibility  string  `json:"visibility"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
+		forum := *stored
+		if req.Title != nil {
+			if strings.TrimSpace(*req.Title) == "" {
+				http.Error(w, "Title is required", http.StatusBadRequest)
+				return
+			}
+			forum.Title = *req.Title
+		}
+		if req.Description != nil {
+			forum.Description = *req.Description
+		}
+		if req.Visibility != "" {
+			if !business.ValidVisibility(req.Visibility) {
+				http.Error(w, "Invalid visibility", http.StatusBadRequest)
+				return
+			}
+			forum.Visibility = req.Visibility
+		}
 
 		if err := repo.Update(id, forum); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
+		broadcastToForum(id, WSMessage{
+			Type: events.ForumUpdated,
+			Payload: map[string]interface{}{
+				"forum": forum,
+			},
+		})
+		eventBus.Publish(events.Event{Type: events.ForumUpdated, ForumID: id, Forum: &forum})
+
 		w.WriteHeader(http.StatusOK)
 	}
 }
 
-// DeleteForum (новая функция)
-func DeleteForum(repo *repository.ForumsRepo) http.HandlerFunc {
+// DeleteForum переносит форум в корзину
+func DeleteForum(repo repository.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		id, _ := strconv.Atoi(vars["id"])
 
-		if err := repo.Delete(id); err != nil {
+		user := userFromRequest(r, repo)
+		if user == nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		forum, err := repo.GetByID(id)
+		if err != nil {
+			http.Error(w, "Forum not found", http.StatusNotFound)
+			return
+		}
+		if !rbac.CanInForum(user, rbac.ForumDelete, forumAccess(repo, forum, user)) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		if err := repo.Delete(id, user.ID); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
+		broadcastToForum(id, WSMessage{
+			Type: events.ForumDeleted,
+			Payload: map[string]interface{}{
+				"forumId": id,
+			},
+		})
+		eventBus.Publish(events.Event{Type: events.ForumDeleted, ForumID: id})
+
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
-func GetMessages(repo *repository.ForumsRepo) http.HandlerFunc {
+func GetMessages(repo repository.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		forumID, err := strconv.Atoi(vars["id"])
@@ -434,49 +561,44 @@ func GetMessages(repo *repository.ForumsRepo) http.HandlerFunc {
 			return
 		}
 
-		// Получаем сообщения
-		messages, err := repo.GetMessages(forumID)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+		// Сообщения форума для участников страница сама подгружает с токеном
+		user := userFromRequest(r, repo)
+		var messages []business.Message
+		if rbac.CanReadForum(user, forum) {
+			messages, err = repo.GetMessages(forumID)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
 		}
 
 		// Получаем текущего пользователя и роль из JWT токена
-		authHeader := r.Header.Get("Authorization")
-		var currentUser, currentRole string
-		if authHeader != "" {
-			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-			if claims, err := jwt.ParseToken(tokenString, "your-secret-key"); err == nil {
-				if user, err := repo.GetUserByID(claims.UserID); err == nil {
-					currentUser = user.Username
-					currentRole = user.Role
-				}
-			}
-		}
+		current := currentUserInfo(user, forumAccess(repo, forum, user))
 
 		// Рендерим шаблон (если нужно использовать роль в шаблоне)
 		data := struct {
-			Forum       *business.Forum
-			Messages    []business.Message
-			CurrentUser string
-			CurrentRole string
+			Forum              *business.Forum
+			Messages           []business.Message
+			CurrentUser        string
+			CurrentRole        string
+			CurrentPermissions []rbac.Permission
 		}{
-			Forum:       forum,
-			Messages:    messages,
-			CurrentUser: currentUser,
-			CurrentRole: currentRole,
+			Forum:              forum,
+			Messages:           messages,
+			CurrentUser:        current.Username,
+			CurrentRole:        current.Role,
+			CurrentPermissions: current.Permissions,
 		}
 
 		renderTemplate(w, "message_list.html", data)
 	}
 }
 
-func UpdateMessage(repo *repository.ForumsRepo) http.HandlerFunc {
+func UpdateMessage(repo repository.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Извлекаем ID сообщения из URL
 		vars := mux.Vars(r)
 		messageID, err := strconv.Atoi(vars["message_id"])
-		fmt.Println(messageID)
 		if err != nil {
 			http.Error(w, "Invalid message ID", http.StatusBadRequest)
 			return
@@ -490,18 +612,13 @@ func UpdateMessage(repo *repository.ForumsRepo) http.HandlerFunc {
 			http.Error(w, "Invalid request body", http.StatusBadRequest)
 			return
 		}
+		if strings.TrimSpace(request.Content) == "" {
+			http.Error(w, "Content is required", http.StatusBadRequest)
+			return
+		}
 
 		// Получаем пользователя из токена
-		authHeader := r.Header.Get("Authorization")
-		var user *business.User
-		if authHeader != "" {
-			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-			if claims, err := jwt.ParseToken(tokenString, "your-secret-key"); err == nil {
-				if u, err := repo.GetUserByID(claims.UserID); err == nil {
-					user = u
-				}
-			}
-		}
+		user := userFromRequest(r, repo)
 		if user == nil {
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
@@ -514,18 +631,28 @@ func UpdateMessage(repo *repository.ForumsRepo) http.HandlerFunc {
 			return
 		}
 
-		// Проверяем права: автор или admin
-		if user.Username != msg.Author && user.Role != "admin" {
+		// Проверяем права: автор или модератор; в архиве править нельзя
+		forum, status, errMsg := forumForWrite(repo, msg.ForumID, user)
+		if status != 0 {
+			http.Error(w, errMsg, status)
+			return
+		}
+		if !rbac.CanOwnedInForum(user, rbac.MessageUpdate, msg.AuthorID, forumAccess(repo, forum, user)) {
 			http.Error(w, "Forbidden", http.StatusForbidden)
 			return
 		}
 
 		// Обновляем сообщение в репозитории
-		updatedMessage, err := repo.PutMessage(messageID, request.Content)
+		updatedMessage, err := repo.PutMessage(messageID, request.Content, user.ID)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		broadcastToForum(updatedMessage.ForumID, WSMessage{
+			Type:    events.MessageUpdated,
+			Payload: updatedMessage,
+		})
+		eventBus.Publish(events.Event{Type: events.MessageUpdated, ForumID: updatedMessage.ForumID, Message: updatedMessage})
 
 		// Возвращаем обновленное сообщение
 		w.Header().Set("Content-Type", "application/json")
@@ -533,9 +660,57 @@ func UpdateMessage(repo *repository.ForumsRepo) http.HandlerFunc {
 	}
 }
 
+// GetMessageRevisions отдает историю правок сообщения его автору и администратору
+func GetMessageRevisions(repo repository.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		forumID, _ := strconv.Atoi(vars["id"])
+		messageID, err := strconv.Atoi(vars["message_id"])
+		if err != nil {
+			http.Error(w, "Invalid message ID", http.StatusBadRequest)
+			return
+		}
+
+		user := userFromRequest(r, repo)
+		if user == nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		msg, err := repo.GetMessageByID(messageID)
+		if err != nil || msg.ForumID != forumID {
+			http.Error(w, "Message not found", http.StatusNotFound)
+			return
+		}
+
+		// Проверяем права: автор или модератор
+		forum, status, errMsg := forumForRead(repo, msg.ForumID, user)
+		if status != 0 {
+			http.Error(w, errMsg, status)
+			return
+		}
+		if !rbac.CanOwnedInForum(user, rbac.MessageUpdate, msg.AuthorID, forumAccess(repo, forum, user)) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		revisions, err := repo.GetMessageRevisions(messageID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message":   msg,
+			"revisions": revisions,
+		})
+	}
+}
+
 // Отправка сообщения
 
-func DeleteMessage(repo *repository.ForumsRepo) http.HandlerFunc {
+func DeleteMessage(repo repository.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		messageID, err := strconv.Atoi(vars["message_id"])
@@ -545,16 +720,7 @@ func DeleteMessage(repo *repository.ForumsRepo) http.HandlerFunc {
 		}
 
 		// Получаем пользователя из токена
-		authHeader := r.Header.Get("Authorization")
-		var user *business.User
-		if authHeader != "" {
-			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-			if claims, err := jwt.ParseToken(tokenString, "your-secret-key"); err == nil {
-				if u, err := repo.GetUserByID(claims.UserID); err == nil {
-					user = u
-				}
-			}
-		}
+		user := userFromRequest(r, repo)
 		if user == nil {
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
@@ -567,120 +733,115 @@ func DeleteMessage(repo *repository.ForumsRepo) http.HandlerFunc {
 			return
 		}
 
-		// Проверяем права: автор или admin
-		if user.Username != msg.Author && user.Role != "admin" {
+		// Проверяем права: автор или модератор; удалять можно и в архиве
+		forum, status, errMsg := forumForRead(repo, msg.ForumID, user)
+		if status != 0 {
+			http.Error(w, errMsg, status)
+			return
+		}
+		if !rbac.CanOwnedInForum(user, rbac.MessageDelete, msg.AuthorID, forumAccess(repo, forum, user)) {
 			http.Error(w, "Forbidden", http.StatusForbidden)
 			return
 		}
 
-		err = repo.DeleteMessage(messageID)
+		err = repo.DeleteMessage(messageID, user.ID)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		broadcastToForum(msg.ForumID, WSMessage{
+			Type: events.MessageDeleted,
+			Payload: map[string]interface{}{
+				"messageId": messageID,
+			},
+		})
+		eventBus.Publish(events.Event{Type: events.MessageDeleted, ForumID: msg.ForumID, MessageID: messageID})
 
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
+func loadTemplates() *template.Template {
+	templatesOnce.Do(func() {
+		templates = template.Must(template.ParseGlob(TemplatesPattern))
+	})
+	return templates
+}
+
 func renderTemplate(w http.ResponseWriter, tmpl string, data interface{}) {
-	err := templates.ExecuteTemplate(w, tmpl, data)
+	err := loadTemplates().ExecuteTemplate(w, tmpl, data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
-func serveGlobalChat(w http.ResponseWriter, r *http.Request, repo *repository.ForumsRepo) {
-	conn, err := globalChatUpgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Printf("Global chat WebSocket upgrade error: %v", err)
+// serveGlobalChat подключает к мини-чату. Писать может только пользователь
+// с токеном, автор сообщения берется из токена.
+func serveGlobalChat(w http.ResponseWriter, r *http.Request, repo repository.Store) {
+	user, ok := authenticateWS(w, r, repo)
+	if !ok {
 		return
 	}
 
-	log.Println("Новое WebSocket соединение установлено")
-
-	defer func() {
-		log.Println("WebSocket соединение закрыто")
-		globalChatMu.Lock()
-		delete(globalChatClients, conn)
-		globalChatMu.Unlock()
-		conn.Close()
-	}()
-
-	// Регистрация клиента
-	globalChatMu.Lock()
-	globalChatClients[conn] = true
-	globalChatMu.Unlock()
-
-	// Загрузка истории из БД (последние 100 сообщений)
-	history, err := repo.GetGlobalChatHistory(100)
-	if err != nil {
-		log.Printf("Ошибка загрузки истории чата: %v", err)
-	} else {
-		// Конвертируем в GlobalChatMessage и отправляем
-		for _, msg := range history {
-			chatMsg := GlobalChatMessage{
-				Author:    msg.Author,
-				Content:   msg.Content,
-				CreatedAt: msg.CreatedAt,
+	hub.Serve(w, r, ws.GlobalChatRoom, ws.Handlers{
+		// История из БД (последние 100 сообщений) уходит раньше новых сообщений
+		OnConnect: func(c *ws.Client) {
+			history, _, err := repo.GetGlobalChatPage(repository.PageRequest{Backward: true, Limit: 100})
+			if err != nil {
+				log.Printf("Ошибка загрузки истории чата: %v", err)
+				return
 			}
-			if err := conn.WriteJSON(chatMsg); err != nil {
-				log.Printf("Ошибка отправки истории: %v", err)
-				break
+			for _, msg := range history {
+				c.SendJSON(GlobalChatMessage{
+					Author:    msg.Author,
+					Content:   msg.Content,
+					CreatedAt: msg.CreatedAt,
+				})
+			}
+		},
+		OnMessage: func(c *ws.Client, data []byte) {
+			if user == nil {
+				c.SendJSON(WSMessage{Type: "error", Payload: "Authentication required"})
+				return
+			}
+			if !rbac.Can(user, rbac.ChatPost) {
+				c.SendJSON(WSMessage{Type: "error", Payload: "Forbidden"})
+				return
 			}
-		}
-	}
 
-	// Чтение новых сообщений
-	for {
-		var msg GlobalChatMessage
-		if err := conn.ReadJSON(&msg); err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
+			var req GlobalChatMessageRequest
+			if err := json.Unmarshal(data, &req); err != nil {
 				log.Printf("Global chat error: %v", err)
+				return
+			}
+			if strings.TrimSpace(req.Content) == "" {
+				return
+			}
+			msg := GlobalChatMessage{
+				Author:    user.Username,
+				Content:   req.Content,
+				CreatedAt: time.Now(),
 			}
-			break
-		}
-
-		// Сохраняем в БД через репозиторий
-		_, err := repo.CreateGlobalMessage(business.GlobalMessage{
-			Author:    msg.Author,
-			Content:   msg.Content,
-			CreatedAt: time.Now(),
-		})
-		if err != nil {
-			log.Printf("Ошибка сохранения сообщения: %v", err)
-		}
-
-		// Рассылка всем клиентам
-		globalChatBroadcast <- msg
-	}
-}
-
-func handleGlobalChatMessages() {
-	for {
-		msg := <-globalChatBroadcast
-		globalChatMu.Lock()
-
-		// Обновляем историю в памяти (опционально)
-		globalChatHistory = append(globalChatHistory, msg)
-		if len(globalChatHistory) > 100 {
-			globalChatHistory = globalChatHistory[1:]
-		}
 
-		// Рассылка
-		for client := range globalChatClients {
-			if err := client.WriteJSON(msg); err != nil {
-				log.Printf("Ошибка отправки: %v", err)
-				client.Close()
-				delete(globalChatClients, client)
+			// Сохраняем в БД через репозиторий
+			_, err := repo.CreateGlobalMessage(business.GlobalMessage{
+				AuthorID:  user.ID,
+				Author:    msg.Author,
+				Content:   msg.Content,
+				CreatedAt: msg.CreatedAt,
+			})
+			if err != nil {
+				log.Printf("Ошибка сохранения сообщения: %v", err)
 			}
-		}
-		globalChatMu.Unlock()
-	}
+
+			// Рассылка всем клиентам
+			hub.PublishJSON(ws.GlobalChatRoom, msg)
+		},
+	})
 }
 
 // Обработчик POST-запроса для глобального чата
-func handleGlobalChatMessage(repo *repository.ForumsRepo) http.HandlerFunc {
+func handleGlobalChatMessage(repo repository.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
@@ -699,14 +860,26 @@ func handleGlobalChatMessage(repo *repository.ForumsRepo) http.HandlerFunc {
 		defer r.Body.Close()
 
 		// 3. Валидация
-		if strings.TrimSpace(req.Author) == "" || strings.TrimSpace(req.Content) == "" {
-			http.Error(w, `{"error": "Username and text are required"}`, http.StatusBadRequest)
+		if strings.TrimSpace(req.Content) == "" {
+			http.Error(w, `{"error": "Text is required"}`, http.StatusBadRequest)
+			return
+		}
+
+		// Автор — владелец токена, а не поле запроса
+		user := userFromRequest(r, repo)
+		if user == nil {
+			http.Error(w, `{"error": "Unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		if !rbac.Can(user, rbac.ChatPost) {
+			http.Error(w, `{"error": "Forbidden"}`, http.StatusForbidden)
 			return
 		}
 
 		// 4. Создаем структуру business.GlobalMessage для сохранения в БД
 		msgBusiness := business.GlobalMessage{
-			Author:    req.Author,
+			AuthorID:  user.ID,
+			Author:    user.Username,
 			Content:   req.Content,
 			CreatedAt: time.Now(),
 		}
@@ -721,20 +894,20 @@ func handleGlobalChatMessage(repo *repository.ForumsRepo) http.HandlerFunc {
 
 		// 6. Создаем структуру GlobalChatMessage для отправки в WebSocket
 		msgWebSocket := GlobalChatMessage{
-			Author:    req.Author,
+			Author:    user.Username,
 			Content:   req.Content,
 			CreatedAt: time.Now(),
 		}
 		log.Printf("Sending message %v to websocket", msgWebSocket)
 
 		// 7. Отправляем в WebSocket
-		globalChatBroadcast <- msgWebSocket
+		hub.PublishJSON(ws.GlobalChatRoom, msgWebSocket)
 
 		// 8. Успешный ответ
 		w.WriteHeader(http.StatusCreated)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"id":        id,
-			"username":  req.Author,
+			"username":  user.Username,
 			"text":      req.Content,
 			"timestamp": time.Now(),
 		})
@@ -742,8 +915,50 @@ func handleGlobalChatMessage(repo *repository.ForumsRepo) http.HandlerFunc {
 	}
 }
 
+// GetMessageTree возвращает сообщения форума деревом ответов.
+// Параметр depth ограничивает глубину (по умолчанию 5, не больше 10).
+func GetMessageTree(repo repository.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		forumID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			sendError(w, http.StatusBadRequest, "Invalid forum ID")
+			return
+		}
+
+		depth := defaultReplyDepth
+		if v := r.URL.Query().Get("depth"); v != "" {
+			depth, err = strconv.Atoi(v)
+			if err != nil || depth < 1 {
+				sendError(w, http.StatusBadRequest, "depth must be a positive integer")
+				return
+			}
+			if depth > maxReplyDepth {
+				depth = maxReplyDepth
+			}
+		}
+
+		if _, status, errMsg := forumForRead(repo, forumID, userFromRequest(r, repo)); status != 0 {
+			sendError(w, status, errMsg)
+			return
+		}
+
+		messages, err := repo.GetMessages(forumID)
+		if err != nil {
+			sendError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"depth":    depth,
+			"messages": business.BuildMessageTree(messages, depth),
+		})
+	}
+}
+
 // Новый API-эндпоинт для загрузки сообщений с учетом токена
-func GetMessagesAPI(repo *repository.ForumsRepo) http.HandlerFunc {
+func GetMessagesAPI(repo repository.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		forumID, err := strconv.Atoi(vars["id"])
@@ -752,32 +967,83 @@ func GetMessagesAPI(repo *repository.ForumsRepo) http.HandlerFunc {
 			return
 		}
 
-		messages, err := repo.GetMessages(forumID)
+		// По умолчанию отдается последняя страница, prev ведет к более ранним сообщениям
+		page, err := parsePageRequest(r, true)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		user := userFromRequest(r, repo)
+		forum, status, errMsg := forumForRead(repo, forumID, user)
+		if status != 0 {
+			http.Error(w, errMsg, status)
+			return
+		}
+
+		messages, hasMore, err := repo.GetMessagesPage(forumID, page)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		if messages == nil {
+			messages = []business.Message{}
+		}
 
-		// Получаем текущего пользователя из JWT токена
-		authHeader := r.Header.Get("Authorization")
-		fmt.Println(authHeader)
-		var currentUser, currentRole string
-		if authHeader != "" {
-			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-			if claims, err := jwt.ParseToken(tokenString, "your-secret-key"); err == nil {
-				if user, err := repo.GetUserByID(claims.UserID); err == nil {
-					currentUser = user.Username
-					currentRole = user.Role
-				}
-			}
+		var first, last *repository.Cursor
+		if len(messages) > 0 {
+			first = &repository.Cursor{CreatedAt: messages[0].CreatedAt, ID: messages[0].ID}
+			last = &repository.Cursor{CreatedAt: messages[len(messages)-1].CreatedAt, ID: messages[len(messages)-1].ID}
 		}
-		fmt.Println(currentUser)
-		fmt.Println(currentRole)
+		next, prev := pageLinks(r, page, first, last, hasMore)
+
+		// Получаем текущего пользователя из JWT токена
+		current := currentUserInfo(user, forumAccess(repo, forum, user))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"forum":              forum,
+			"messages":           messages,
+			"currentUser":        current.Username,
+			"currentUserId":      current.ID,
+			"currentRole":        current.Role,
+			"currentPermissions": current.Permissions,
+			"next":               next,
+			"prev":               prev,
+		})
+	}
+}
+
+// GetGlobalChatHistory отдает историю мини-чата постранично, начиная с последних сообщений
+func GetGlobalChatHistory(repo repository.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
+
+		page, err := parsePageRequest(r, true)
+		if err != nil {
+			sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		history, hasMore, err := repo.GetGlobalChatPage(page)
+		if err != nil {
+			sendError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if history == nil {
+			history = []business.GlobalMessage{}
+		}
+
+		var first, last *repository.Cursor
+		if len(history) > 0 {
+			first = &repository.Cursor{CreatedAt: history[0].CreatedAt, ID: history[0].ID}
+			last = &repository.Cursor{CreatedAt: history[len(history)-1].CreatedAt, ID: history[len(history)-1].ID}
+		}
+		next, prev := pageLinks(r, page, first, last, hasMore)
+
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"messages":    messages,
-			"currentUser": currentUser,
-			"currentRole": currentRole,
+			"messages": history,
+			"next":     next,
+			"prev":     prev,
 		})
 	}
 }
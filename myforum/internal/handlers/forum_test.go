@@ -0,0 +1,707 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/jaxxiy/myforum/internal/broadcast"
+	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/events"
+	"github.com/jaxxiy/myforum/internal/mail"
+	"github.com/jaxxiy/myforum/internal/repository"
+	"github.com/jaxxiy/myforum/internal/ws"
+	"github.com/jaxxiy/myforum/pkg/jwt"
+)
+
+var testTokens, _ = jwt.NewManager(jwt.Config{Key: "test-secret"})
+
+func TestMain(m *testing.M) {
+	TemplatesPattern = "../../templates/*.html"
+	os.Exit(m.Run())
+}
+
+type testEnv struct {
+	store  *repository.MemoryStore
+	router *mux.Router
+
+	alice, bob, admin int
+	forumID           int
+	// Письма сервиса авторизации; только в newAuthTestEnv
+	mail *mail.Memory
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	return newTestEnvWithOptions(t, Options{Tokens: testTokens})
+}
+
+func newTestEnvWithOptions(t *testing.T, opts Options) *testEnv {
+	t.Helper()
+
+	store := repository.NewMemoryStore()
+	env := &testEnv{store: store, router: mux.NewRouter()}
+
+	users := store.Users()
+	for _, u := range []struct {
+		id   *int
+		name string
+		role string
+	}{
+		{&env.alice, "alice", "user"},
+		{&env.bob, "bob", "user"},
+		{&env.admin, "root", "admin"},
+	} {
+		id, err := users.Create(business.User{Username: u.name, Email: u.name + "@example.com", Role: u.role})
+		if err != nil {
+			t.Fatalf("create user %s: %v", u.name, err)
+		}
+		*u.id = id
+	}
+
+	forumID, err := store.Create(business.Forum{Title: "Golang", Description: "Все о Go"})
+	if err != nil {
+		t.Fatalf("create forum: %v", err)
+	}
+	env.forumID = forumID
+
+	RegisterForumHandlers(env.router, store, store.Topics(), events.NewBus(), ws.NewHub(broadcast.NewLocal()), opts)
+	return env
+}
+
+// do выполняет запрос от имени пользователя; userID == 0 — анонимный запрос
+func (e *testEnv) do(t *testing.T, method, path, body string, userID int) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if userID != 0 {
+		req.Header.Set("Authorization", "Bearer "+tokenFor(t, userID))
+	}
+
+	rec := httptest.NewRecorder()
+	e.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func tokenFor(t *testing.T, userID int) string {
+	t.Helper()
+	token, err := testTokens.Issue(jwt.Claims{UserID: userID}, time.Hour)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	return token
+}
+
+func (e *testEnv) createMessage(t *testing.T, authorID int, content string, createdAt time.Time) int {
+	t.Helper()
+
+	author, err := e.store.GetUserByID(authorID)
+	if err != nil {
+		t.Fatalf("get author: %v", err)
+	}
+	id, err := e.store.CreateMessage(business.Message{
+		ForumID:   e.forumID,
+		AuthorID:  authorID,
+		Author:    author.Username,
+		Content:   content,
+		CreatedAt: createdAt,
+	})
+	if err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+	return id
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+}
+
+func TestForumPages(t *testing.T) {
+	env := newTestEnv(t)
+
+	rec := env.do(t, "GET", "/api/forums", "", 0)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Golang") {
+		t.Fatalf("list forums: %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = env.do(t, "GET", fmt.Sprintf("/api/forums/%d", env.forumID), "", 0)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Все о Go") {
+		t.Fatalf("get forum: %d %q", rec.Code, rec.Body.String())
+	}
+
+	if rec := env.do(t, "GET", "/api/forums/999", "", 0); rec.Code != http.StatusNotFound {
+		t.Fatalf("get missing forum: got %d, want 404", rec.Code)
+	}
+}
+
+func TestForumCRUD(t *testing.T) {
+	env := newTestEnv(t)
+
+	form := url.Values{"title": {"Rust"}, "description": {"Про Rust"}}
+	createForum := func(userID int) int {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/api/forums", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if userID != 0 {
+			req.Header.Set("Authorization", "Bearer "+tokenFor(t, userID))
+		}
+		rec := httptest.NewRecorder()
+		env.router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := createForum(0); code != http.StatusUnauthorized {
+		t.Fatalf("create forum anonymously: got %d, want 401", code)
+	}
+	form.Set("title", "  ")
+	if code := createForum(env.alice); code != http.StatusBadRequest {
+		t.Fatalf("create forum without title: got %d, want 400", code)
+	}
+	form.Set("title", "Rust")
+	if code := createForum(env.alice); code != http.StatusSeeOther {
+		t.Fatalf("create forum: got %d, want 303", code)
+	}
+
+	forums, _ := env.store.GetAll()
+	if len(forums) != 2 || forums[1].Title != "Rust" || forums[1].OwnerID != env.alice {
+		t.Fatalf("forums after create: %+v", forums)
+	}
+	newID := forums[1].ID
+
+	if rec := env.do(t, "PUT", fmt.Sprintf("/api/forums/%d", newID), `{"title":"Hacked"}`, env.bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("update by other user: got %d, want 403", rec.Code)
+	}
+
+	rec := env.do(t, "PUT", fmt.Sprintf("/api/forums/%d", newID), `{"title":"Rust 2024","description":"Edition"}`, env.alice)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update forum: %d %q", rec.Code, rec.Body.String())
+	}
+	if f, _ := env.store.GetByID(newID); f.Title != "Rust 2024" {
+		t.Fatalf("forum title after update: %q", f.Title)
+	}
+
+	// Поля, которых нет в запросе, не меняются; пустое название не принимается
+	if rec := env.do(t, "PUT", fmt.Sprintf("/api/forums/%d", newID), `{"visibility":"archived"}`, env.alice); rec.Code != http.StatusOK {
+		t.Fatalf("update visibility: %d %q", rec.Code, rec.Body.String())
+	}
+	if f, _ := env.store.GetByID(newID); f.Title != "Rust 2024" || f.Description != "Edition" || f.Visibility != business.VisibilityArchived {
+		t.Fatalf("forum after visibility update: %+v", f)
+	}
+	if rec := env.do(t, "PUT", fmt.Sprintf("/api/forums/%d", newID), `{"title":" "}`, env.alice); rec.Code != http.StatusBadRequest {
+		t.Fatalf("update with empty title: got %d, want 400", rec.Code)
+	}
+
+	if rec := env.do(t, "DELETE", fmt.Sprintf("/api/forums/%d", newID), "", env.admin); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete forum: got %d, want 204", rec.Code)
+	}
+	rec = env.do(t, "DELETE", fmt.Sprintf("/api/forums/%d", newID), "", env.admin)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("delete missing forum: %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPostMessage(t *testing.T) {
+	env := newTestEnv(t)
+	path := fmt.Sprintf("/api/forums/%d/messages", env.forumID)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		userID int
+		want   int
+	}{
+		{"anonymous", path, `{"content":"hi"}`, 0, http.StatusUnauthorized},
+		{"other author", path, fmt.Sprintf(`{"author_id":%d,"content":"hi"}`, env.alice), env.bob, http.StatusForbidden},
+		{"empty content", path, `{"content":" "}`, env.alice, http.StatusBadRequest},
+		{"missing parent", path, `{"content":"hi","parent_id":42}`, env.alice, http.StatusBadRequest},
+		{"missing forum", "/api/forums/999/messages", `{"content":"hi"}`, env.alice, http.StatusNotFound},
+		// Имя автора из тела запроса не учитывается
+		{"author", path, `{"author":"bob","content":"hi"}`, env.alice, http.StatusCreated},
+		{"admin for author", path, fmt.Sprintf(`{"author_id":%d,"content":"by admin"}`, env.alice), env.admin, http.StatusCreated},
+		{"admin for unknown author", path, `{"author_id":999,"content":"hi"}`, env.admin, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := env.do(t, "POST", tt.path, tt.body, tt.userID)
+			if rec.Code != tt.want {
+				t.Fatalf("got %d, want %d: %q", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+
+	messages, _ := env.store.GetMessages(env.forumID)
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(messages))
+	}
+	if messages[0].TopicID == 0 {
+		t.Fatalf("message without topic was not put into the default topic")
+	}
+	for _, m := range messages {
+		if m.AuthorID != env.alice || m.Author != "alice" {
+			t.Errorf("message %q: author = %q (%d), want alice (%d)", m.Content, m.Author, m.AuthorID, env.alice)
+		}
+	}
+}
+
+func TestReplyAndQuote(t *testing.T) {
+	env := newTestEnv(t)
+	parentID := env.createMessage(t, env.bob, "Вопрос", time.Now())
+	path := fmt.Sprintf("/api/forums/%d/messages", env.forumID)
+
+	body := fmt.Sprintf(`{"content":"Ответ","parent_id":%d,"quote_id":%d}`, parentID, parentID)
+	rec := env.do(t, "POST", path, body, env.alice)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("post reply: %d %q", rec.Code, rec.Body.String())
+	}
+	var reply business.Message
+	decode(t, rec, &reply)
+	if reply.ParentID != parentID || reply.Quote == nil || reply.Quote.Content != "Вопрос" {
+		t.Fatalf("unexpected reply: %+v", reply)
+	}
+
+	rec = env.do(t, "GET", path+"/tree", "", 0)
+	var tree struct {
+		Messages []business.MessageNode `json:"messages"`
+	}
+	decode(t, rec, &tree)
+	if len(tree.Messages) != 1 || len(tree.Messages[0].Replies) != 1 || tree.Messages[0].Replies[0].ID != reply.ID {
+		t.Fatalf("unexpected tree: %+v", tree.Messages)
+	}
+}
+
+func TestUpdateAndDeleteMessage(t *testing.T) {
+	env := newTestEnv(t)
+	msgID := env.createMessage(t, env.alice, "Черновик", time.Now())
+	path := fmt.Sprintf("/api/forums/%d/messages/%d", env.forumID, msgID)
+
+	if rec := env.do(t, "PUT", path, `{"content":"Чужая правка"}`, 0); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous update: got %d, want 401", rec.Code)
+	}
+	if rec := env.do(t, "PUT", path, `{"content":"Чужая правка"}`, env.bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("update by other user: got %d, want 403", rec.Code)
+	}
+	if rec := env.do(t, "PUT", path, `{"content":"  "}`, env.alice); rec.Code != http.StatusBadRequest {
+		t.Fatalf("update with empty content: got %d, want 400", rec.Code)
+	}
+	if revisions, _ := env.store.GetMessageRevisions(msgID); len(revisions) != 0 {
+		t.Fatalf("empty update recorded revisions: %+v", revisions)
+	}
+
+	rec := env.do(t, "PUT", path, `{"content":"Итог"}`, env.alice)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update by author: %d %q", rec.Code, rec.Body.String())
+	}
+	var updated business.Message
+	decode(t, rec, &updated)
+	if updated.Content != "Итог" {
+		t.Fatalf("content after update: %q", updated.Content)
+	}
+
+	if rec := env.do(t, "DELETE", path, "", env.bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("delete by other user: got %d, want 403", rec.Code)
+	}
+	if rec := env.do(t, "DELETE", path, "", env.admin); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete by admin: got %d, want 204", rec.Code)
+	}
+	if rec := env.do(t, "DELETE", path, "", env.admin); rec.Code != http.StatusNotFound {
+		t.Fatalf("delete missing message: got %d, want 404", rec.Code)
+	}
+}
+
+// Владелец сообщения определяется по ID: новый пользователь с именем
+// удаленного автора чужие сообщения не получает
+func TestMessageOwnershipByID(t *testing.T) {
+	env := newTestEnv(t)
+	msgID := env.createMessage(t, env.alice, "Старое", time.Now())
+	path := fmt.Sprintf("/api/forums/%d/messages/%d", env.forumID, msgID)
+
+	users := env.store.Users()
+	if err := users.DeleteUser(env.alice); err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+	newAlice, err := users.Create(business.User{Username: "alice", Email: "alice2@example.com", Role: "user"})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	if rec := env.do(t, "PUT", path, `{"content":"Теперь мое"}`, newAlice); rec.Code != http.StatusForbidden {
+		t.Fatalf("update by namesake: got %d, want 403", rec.Code)
+	}
+	if rec := env.do(t, "DELETE", path, "", newAlice); rec.Code != http.StatusForbidden {
+		t.Fatalf("delete by namesake: got %d, want 403", rec.Code)
+	}
+
+	msg, err := env.store.GetMessageByID(msgID)
+	if err != nil {
+		t.Fatalf("get message: %v", err)
+	}
+	if msg.AuthorID != 0 || msg.Author != business.DeletedAuthor {
+		t.Errorf("author after delete = %d %q", msg.AuthorID, msg.Author)
+	}
+
+	// Новые сообщения принадлежат новому пользователю
+	rec := env.do(t, "POST", fmt.Sprintf("/api/forums/%d/messages", env.forumID), `{"content":"Новое"}`, newAlice)
+	var created business.Message
+	decode(t, rec, &created)
+	if created.AuthorID != newAlice || created.Author != "alice" {
+		t.Errorf("new message author = %d %q", created.AuthorID, created.Author)
+	}
+}
+
+func TestMessageRevisions(t *testing.T) {
+	env := newTestEnv(t)
+	msgID := env.createMessage(t, env.alice, "Первая версия", time.Now())
+	path := fmt.Sprintf("/api/forums/%d/messages/%d", env.forumID, msgID)
+
+	for _, edit := range []struct {
+		userID  int
+		content string
+	}{
+		{env.alice, "Вторая версия"},
+		{env.admin, "Третья версия"},
+	} {
+		rec := env.do(t, "PUT", path, fmt.Sprintf(`{"content":%q}`, edit.content), edit.userID)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("update: %d %q", rec.Code, rec.Body.String())
+		}
+		var updated business.Message
+		decode(t, rec, &updated)
+		if updated.EditedAt == nil {
+			t.Fatal("edited_at is not set after update")
+		}
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		userID int
+		want   int
+	}{
+		{"anonymous", path + "/revisions", 0, http.StatusUnauthorized},
+		{"other user", path + "/revisions", env.bob, http.StatusForbidden},
+		{"wrong forum", fmt.Sprintf("/api/forums/%d/messages/%d/revisions", env.forumID+1, msgID), env.alice, http.StatusNotFound},
+		{"admin", path + "/revisions", env.admin, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if rec := env.do(t, "GET", tt.path, "", tt.userID); rec.Code != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+
+	rec := env.do(t, "GET", path+"/revisions", "", env.alice)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("revisions for author: %d %q", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Message   business.Message           `json:"message"`
+		Revisions []business.MessageRevision `json:"revisions"`
+	}
+	decode(t, rec, &resp)
+	if resp.Message.Content != "Третья версия" {
+		t.Fatalf("current content = %q", resp.Message.Content)
+	}
+	if len(resp.Revisions) != 2 {
+		t.Fatalf("got %d revisions, want 2: %+v", len(resp.Revisions), resp.Revisions)
+	}
+	for i, want := range []struct {
+		content, editor string
+	}{
+		{"Первая версия", "alice"},
+		{"Вторая версия", "root"},
+	} {
+		if got := resp.Revisions[i]; got.Content != want.content || got.Editor != want.editor {
+			t.Errorf("revision %d = %+v, want %q by %s", i, got, want.content, want.editor)
+		}
+	}
+}
+
+func TestMessagesListPagination(t *testing.T) {
+	env := newTestEnv(t)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 1; i <= 5; i++ {
+		env.createMessage(t, env.alice, fmt.Sprintf("m%d", i), start.Add(time.Duration(i)*time.Minute))
+	}
+
+	type page struct {
+		Messages    []business.Message `json:"messages"`
+		CurrentUser string             `json:"currentUser"`
+		Next        string             `json:"next"`
+		Prev        string             `json:"prev"`
+	}
+	contents := func(p page) string {
+		var s []string
+		for _, m := range p.Messages {
+			s = append(s, m.Content)
+		}
+		return strings.Join(s, ",")
+	}
+
+	// Без курсора отдается последняя страница
+	rec := env.do(t, "GET", fmt.Sprintf("/api/forums/%d/messages-list?limit=2", env.forumID), "", env.alice)
+	var last page
+	decode(t, rec, &last)
+	if got := contents(last); got != "m4,m5" || last.Next != "" || last.Prev == "" || last.CurrentUser != "alice" {
+		t.Fatalf("last page: %q next=%q prev=%q user=%q", got, last.Next, last.Prev, last.CurrentUser)
+	}
+
+	var middle page
+	decode(t, env.do(t, "GET", last.Prev, "", 0), &middle)
+	if got := contents(middle); got != "m2,m3" || middle.Next == "" || middle.Prev == "" {
+		t.Fatalf("middle page: %q next=%q prev=%q", got, middle.Next, middle.Prev)
+	}
+
+	var first page
+	decode(t, env.do(t, "GET", middle.Prev, "", 0), &first)
+	if got := contents(first); got != "m1" || first.Prev != "" {
+		t.Fatalf("first page: %q prev=%q", got, first.Prev)
+	}
+
+	if rec := env.do(t, "GET", fmt.Sprintf("/api/forums/%d/messages-list?before=bogus", env.forumID), "", 0); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid cursor: got %d, want 400", rec.Code)
+	}
+}
+
+func TestGlobalChat(t *testing.T) {
+	env := newTestEnv(t)
+
+	if rec := env.do(t, "POST", "/api/global-chat", `{"text":" "}`, env.alice); rec.Code != http.StatusBadRequest {
+		t.Fatalf("empty chat message: got %d, want 400", rec.Code)
+	}
+	if rec := env.do(t, "POST", "/api/global-chat", `{"text":"Привет"}`, 0); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous chat message: got %d, want 401", rec.Code)
+	}
+	// Имя в теле запроса игнорируется, автор берется из токена
+	rec := env.do(t, "POST", "/api/global-chat", `{"username":"bob","text":"Привет всем"}`, env.alice)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("post chat message: %d %q", rec.Code, rec.Body.String())
+	}
+
+	var history struct {
+		Messages []business.GlobalMessage `json:"messages"`
+	}
+	decode(t, env.do(t, "GET", "/api/global-chat", "", 0), &history)
+	if len(history.Messages) != 1 || history.Messages[0].Content != "Привет всем" || history.Messages[0].Author != "alice" {
+		t.Fatalf("unexpected history: %+v", history.Messages)
+	}
+}
+
+// dialWS подключается к WebSocket тестового сервера; protocols передают токен
+func dialWS(t *testing.T, srv *httptest.Server, path string, protocols ...string) (*websocket.Conn, int) {
+	t.Helper()
+	dialer := websocket.Dialer{Subprotocols: protocols}
+	conn, resp, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+path, nil)
+	if err != nil {
+		if resp == nil {
+			t.Fatalf("dial %s: %v", path, err)
+		}
+		return nil, resp.StatusCode
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, resp.StatusCode
+}
+
+func readChatMessage(t *testing.T, conn *websocket.Conn) map[string]interface{} {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg map[string]interface{}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return msg
+}
+
+func TestWebSocketAuth(t *testing.T) {
+	env := newTestEnv(t)
+	srv := httptest.NewServer(env.router)
+	defer srv.Close()
+
+	for _, path := range []string{"/ws/global", fmt.Sprintf("/ws/%d", env.forumID)} {
+		if _, code := dialWS(t, srv, path); code != http.StatusUnauthorized {
+			t.Fatalf("anonymous %s: got %d, want 401", path, code)
+		}
+		if _, code := dialWS(t, srv, path+"?token=bogus"); code != http.StatusUnauthorized {
+			t.Fatalf("invalid token %s: got %d, want 401", path, code)
+		}
+	}
+
+	if _, code := dialWS(t, srv, fmt.Sprintf("/ws/%d?token=%s", env.forumID, tokenFor(t, env.bob))); code != http.StatusSwitchingProtocols {
+		t.Fatalf("forum socket with query token: got %d, want 101", code)
+	}
+
+	conn, code := dialWS(t, srv, "/ws/global", ws.BearerProtocol, tokenFor(t, env.alice))
+	if code != http.StatusSwitchingProtocols {
+		t.Fatalf("chat socket with protocol token: got %d, want 101", code)
+	}
+	if conn.Subprotocol() != ws.BearerProtocol {
+		t.Fatalf("server selected subprotocol %q, want %q", conn.Subprotocol(), ws.BearerProtocol)
+	}
+
+	// Поле username от клиента не влияет на автора
+	if err := conn.WriteJSON(map[string]string{"username": "bob", "text": "Привет"}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := readChatMessage(t, conn); msg["username"] != "alice" || msg["text"] != "Привет" {
+		t.Fatalf("unexpected broadcast: %v", msg)
+	}
+}
+
+func TestWebSocketAnonymousReadOnly(t *testing.T) {
+	env := newTestEnvWithOptions(t, Options{Tokens: testTokens, AllowAnonymousWS: true})
+	srv := httptest.NewServer(env.router)
+	defer srv.Close()
+
+	conn, code := dialWS(t, srv, "/ws/global")
+	if code != http.StatusSwitchingProtocols {
+		t.Fatalf("anonymous chat socket: got %d, want 101", code)
+	}
+	if _, code := dialWS(t, srv, "/ws/global?token=bogus"); code != http.StatusUnauthorized {
+		t.Fatalf("invalid token in anonymous mode: got %d, want 401", code)
+	}
+
+	if err := conn.WriteJSON(map[string]string{"text": "Привет"}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := readChatMessage(t, conn); msg["type"] != "error" {
+		t.Fatalf("anonymous message was not rejected: %v", msg)
+	}
+	if history, _ := env.store.GetGlobalChatHistory(10); len(history) != 0 {
+		t.Fatalf("anonymous message was saved: %+v", history)
+	}
+
+	// Анонимный клиент получает сообщения авторизованных
+	if rec := env.do(t, "POST", "/api/global-chat", `{"text":"Всем привет"}`, env.bob); rec.Code != http.StatusCreated {
+		t.Fatalf("post chat message: %d", rec.Code)
+	}
+	if msg := readChatMessage(t, conn); msg["username"] != "bob" {
+		t.Fatalf("unexpected broadcast: %v", msg)
+	}
+}
+
+func TestTopics(t *testing.T) {
+	env := newTestEnv(t)
+	topicsPath := fmt.Sprintf("/api/forums/%d/topics", env.forumID)
+
+	if rec := env.do(t, "POST", topicsPath, `{"title":"Generics"}`, 0); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous create topic: got %d, want 401", rec.Code)
+	}
+	rec := env.do(t, "POST", topicsPath, `{"title":"Generics","description":"Обсуждение"}`, env.alice)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create topic: %d %q", rec.Code, rec.Body.String())
+	}
+	var topic business.Topic
+	decode(t, rec, &topic)
+
+	rec = env.do(t, "POST", fmt.Sprintf("/api/topics/%d/messages", topic.ID), `{"content":"Первый"}`, env.bob)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("post topic message: %d %q", rec.Code, rec.Body.String())
+	}
+	var msg business.Message
+	decode(t, rec, &msg)
+	if msg.Author != "bob" || msg.TopicID != topic.ID {
+		t.Fatalf("unexpected topic message: %+v", msg)
+	}
+
+	if rec := env.do(t, "DELETE", fmt.Sprintf("/api/topics/%d", topic.ID), "", env.alice); rec.Code != http.StatusForbidden {
+		t.Fatalf("delete topic by user: got %d, want 403", rec.Code)
+	}
+	sub, unsubscribe := eventBus.Subscribe(env.forumID)
+	defer unsubscribe()
+	if rec := env.do(t, "DELETE", fmt.Sprintf("/api/topics/%d", topic.ID), "", env.admin); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete topic by admin: got %d, want 204", rec.Code)
+	}
+	// Подписчики узнают об удалении сообщений темы
+	select {
+	case ev := <-sub:
+		if ev.Type != events.MessageDeleted || ev.MessageID != msg.ID {
+			t.Fatalf("unexpected event after topic delete: %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no message_deleted event after topic delete")
+	}
+	if _, err := env.store.GetMessageByID(msg.ID); err == nil {
+		t.Fatalf("topic messages were not deleted with the topic")
+	}
+
+	// Сообщения удаленной темы восстанавливаются из корзины в тему по умолчанию
+	if rec := env.do(t, "POST", fmt.Sprintf("/api/admin/trash/messages/%d/restore", msg.ID), "", env.admin); rec.Code != http.StatusNoContent {
+		t.Fatalf("restore topic message: %d %q", rec.Code, rec.Body.String())
+	}
+	restored, err := env.store.GetMessageByID(msg.ID)
+	if err != nil {
+		t.Fatalf("restored message: %v", err)
+	}
+	defaultID, _ := env.store.Topics().DefaultTopicID(env.forumID)
+	if restored.TopicID != defaultID || restored.Content != "Первый" {
+		t.Fatalf("unexpected restored message: %+v", restored)
+	}
+}
+
+func TestForumSocketEvents(t *testing.T) {
+	env := newTestEnv(t)
+	srv := httptest.NewServer(env.router)
+	defer srv.Close()
+
+	conn, code := dialWS(t, srv, fmt.Sprintf("/ws/%d", env.forumID), ws.BearerProtocol, tokenFor(t, env.bob))
+	if code != http.StatusSwitchingProtocols {
+		t.Fatalf("forum socket: got %d, want 101", code)
+	}
+	for deadline := time.Now().Add(2 * time.Second); hub.Clients(ws.ForumRoom(env.forumID)) != 1; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("forum socket was not registered")
+		}
+	}
+
+	id := env.createMessage(t, env.alice, "Черновик", time.Now())
+	path := fmt.Sprintf("/api/forums/%d/messages/%d", env.forumID, id)
+
+	if rec := env.do(t, "PUT", path, `{"content":"Исправлено"}`, env.alice); rec.Code != http.StatusOK {
+		t.Fatalf("update message: %d %q", rec.Code, rec.Body.String())
+	}
+	msg := readChatMessage(t, conn)
+	payload, _ := msg["payload"].(map[string]interface{})
+	if msg["type"] != "message_updated" || payload["content"] != "Исправлено" || payload["id"] != float64(id) {
+		t.Fatalf("unexpected update event: %v", msg)
+	}
+
+	if rec := env.do(t, "DELETE", path, "", env.alice); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete message: got %d", rec.Code)
+	}
+	msg = readChatMessage(t, conn)
+	payload, _ = msg["payload"].(map[string]interface{})
+	if msg["type"] != "message_deleted" || payload["messageId"] != float64(id) {
+		t.Fatalf("unexpected delete event: %v", msg)
+	}
+
+	forumPath := fmt.Sprintf("/api/forums/%d", env.forumID)
+	if rec := env.do(t, "PUT", forumPath, `{"title":"Go 2","description":"Новое описание"}`, env.admin); rec.Code != http.StatusOK {
+		t.Fatalf("update forum: %d %q", rec.Code, rec.Body.String())
+	}
+	msg = readChatMessage(t, conn)
+	payload, _ = msg["payload"].(map[string]interface{})
+	forum, _ := payload["forum"].(map[string]interface{})
+	if msg["type"] != "forum_updated" || forum["title"] != "Go 2" || forum["created_at"] == "0001-01-01T00:00:00Z" {
+		t.Fatalf("unexpected forum update event: %v", msg)
+	}
+
+	if rec := env.do(t, "DELETE", forumPath, "", env.admin); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete forum: got %d", rec.Code)
+	}
+	msg = readChatMessage(t, conn)
+	payload, _ = msg["payload"].(map[string]interface{})
+	if msg["type"] != "forum_deleted" || payload["forumId"] != float64(env.forumID) {
+		t.Fatalf("unexpected forum delete event: %v", msg)
+	}
+}
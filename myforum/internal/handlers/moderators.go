@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/rbac"
+	"github.com/jaxxiy/myforum/internal/repository"
+)
+
+func registerModeratorHandlers(api *mux.Router, repo repository.Store) {
+	api.HandleFunc("/forums/{id:[0-9]+}/moderators", ListForumModerators(repo)).Methods("GET")
+	api.HandleFunc("/forums/{id:[0-9]+}/moderators", AddForumModerator(repo)).Methods("POST")
+	api.HandleFunc("/forums/{id:[0-9]+}/moderators/{user_id:[0-9]+}", RemoveForumModerator(repo)).Methods("DELETE")
+}
+
+// forumAccess определяет, владелец ли пользователь форума или его модератор
+func forumAccess(repo repository.Store, forum *business.Forum, user *business.User) rbac.ForumAccess {
+	if user == nil {
+		return rbac.ForumAccess{}
+	}
+	if forum.OwnerID == user.ID {
+		return rbac.ForumAccess{Owner: true}
+	}
+	isModerator, err := repo.IsForumModerator(forum.ID, user.ID)
+	if err != nil {
+		log.Printf("Ошибка проверки модератора форума %d: %v", forum.ID, err)
+	}
+	return rbac.ForumAccess{Moderator: isModerator}
+}
+
+// forumForRead загружает форум, содержимое которого видно пользователю.
+// При отказе возвращает HTTP-статус и текст ошибки, иначе статус 0.
+func forumForRead(repo repository.Store, forumID int, user *business.User) (*business.Forum, int, string) {
+	forum, err := repo.GetByID(forumID)
+	if err != nil {
+		return nil, http.StatusNotFound, "Forum not found"
+	}
+	if !rbac.CanReadForum(user, forum) {
+		if user == nil {
+			return nil, http.StatusUnauthorized, "Forum is for members only"
+		}
+		return nil, http.StatusForbidden, "Forbidden"
+	}
+	return forum, 0, ""
+}
+
+// forumForWrite — как forumForRead, но еще отказывает, если форум в архиве
+func forumForWrite(repo repository.Store, forumID int, user *business.User) (*business.Forum, int, string) {
+	forum, status, errMsg := forumForRead(repo, forumID, user)
+	if status != 0 {
+		return nil, status, errMsg
+	}
+	if !rbac.Writable(forum) {
+		return nil, http.StatusForbidden, "Forum is archived"
+	}
+	return forum, 0, ""
+}
+
+// ListForumModerators отдает владельца и модераторов форума тем, кому форум виден
+func ListForumModerators(repo repository.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		forumID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		forum, status, errMsg := forumForRead(repo, forumID, userFromRequest(r, repo))
+		if status != 0 {
+			sendError(w, status, errMsg)
+			return
+		}
+		moderators, err := repo.GetForumModerators(forumID)
+		if err != nil {
+			sendError(w, http.StatusInternalServerError, "Failed to load moderators")
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"owner_id":   forum.OwnerID,
+			"moderators": moderators,
+		})
+	}
+}
+
+// moderatorsManager загружает форум и проверяет право назначать его модераторов.
+// При отказе ответ уже записан и возвращается nil.
+func moderatorsManager(w http.ResponseWriter, r *http.Request, repo repository.Store) *business.Forum {
+	user := userFromRequest(r, repo)
+	if user == nil {
+		sendError(w, http.StatusUnauthorized, "Unauthorized")
+		return nil
+	}
+	forumID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	forum, err := repo.GetByID(forumID)
+	if err != nil {
+		sendError(w, http.StatusNotFound, "Forum not found")
+		return nil
+	}
+	if !rbac.CanInForum(user, rbac.ForumModeratorsManage, forumAccess(repo, forum, user)) {
+		sendError(w, http.StatusForbidden, "Forbidden")
+		return nil
+	}
+	return forum
+}
+
+// AddForumModerator назначает модератора форума; это может владелец форума и администратор
+func AddForumModerator(repo repository.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		forum := moderatorsManager(w, r, repo)
+		if forum == nil {
+			return
+		}
+
+		var req struct {
+			UserID int `json:"user_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		if _, err := repo.GetUserByID(req.UserID); err != nil {
+			sendError(w, http.StatusNotFound, "User not found")
+			return
+		}
+
+		if err := repo.AddForumModerator(forum.ID, req.UserID); err != nil {
+			sendError(w, http.StatusInternalServerError, "Failed to add moderator")
+			return
+		}
+
+		moderators, err := repo.GetForumModerators(forum.ID)
+		if err != nil {
+			sendError(w, http.StatusInternalServerError, "Failed to load moderators")
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"owner_id":   forum.OwnerID,
+			"moderators": moderators,
+		})
+	}
+}
+
+// RemoveForumModerator снимает модератора форума
+func RemoveForumModerator(repo repository.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		forum := moderatorsManager(w, r, repo)
+		if forum == nil {
+			return
+		}
+
+		userID, _ := strconv.Atoi(mux.Vars(r)["user_id"])
+		if err := repo.RemoveForumModerator(forum.ID, userID); err != nil {
+			if errors.Is(err, repository.ErrModeratorNotFound) {
+				sendError(w, http.StatusNotFound, "Moderator not found")
+				return
+			}
+			sendError(w, http.StatusInternalServerError, "Failed to remove moderator")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+func TestForumModerators(t *testing.T) {
+	env := newTestEnv(t)
+	forumID, err := env.store.Create(business.Forum{Title: "Alice's", OwnerID: env.alice})
+	if err != nil {
+		t.Fatalf("create forum: %v", err)
+	}
+	path := fmt.Sprintf("/api/forums/%d/moderators", forumID)
+	addBob := fmt.Sprintf(`{"user_id":%d}`, env.bob)
+
+	if rec := env.do(t, "POST", path, addBob, 0); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous add: got %d", rec.Code)
+	}
+	if rec := env.do(t, "POST", path, addBob, env.bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("add by non-owner: got %d", rec.Code)
+	}
+	if rec := env.do(t, "POST", path, `{"user_id":999}`, env.alice); rec.Code != http.StatusNotFound {
+		t.Fatalf("add unknown user: got %d", rec.Code)
+	}
+
+	rec := env.do(t, "POST", path, addBob, env.alice)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("add by owner: %d %q", rec.Code, rec.Body.String())
+	}
+	var list struct {
+		OwnerID    int                       `json:"owner_id"`
+		Moderators []business.ForumModerator `json:"moderators"`
+	}
+	decode(t, rec, &list)
+	if list.OwnerID != env.alice || len(list.Moderators) != 1 || list.Moderators[0].Username != "bob" {
+		t.Fatalf("moderators = %+v", list)
+	}
+
+	// модератор правит чужие сообщения только в своем форуме
+	moderated, _ := env.store.CreateMessage(business.Message{ForumID: forumID, AuthorID: env.alice, Author: "alice", Content: "hi", CreatedAt: time.Now()})
+	other := env.createMessage(t, env.alice, "hi", time.Now())
+	if rec := env.do(t, "DELETE", fmt.Sprintf("/api/forums/%d/messages/%d", env.forumID, other), "", env.bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("delete outside moderated forum: got %d", rec.Code)
+	}
+	if rec := env.do(t, "DELETE", fmt.Sprintf("/api/forums/%d/messages/%d", forumID, moderated), "", env.bob); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete in moderated forum: %d %q", rec.Code, rec.Body.String())
+	}
+
+	forumPath := fmt.Sprintf("/api/forums/%d", forumID)
+	if rec := env.do(t, "PUT", forumPath, `{"title":"Alice's forum"}`, env.bob); rec.Code != http.StatusOK {
+		t.Fatalf("update by moderator: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := env.do(t, "DELETE", forumPath, "", env.bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("delete forum by moderator: got %d", rec.Code)
+	}
+	if rec := env.do(t, "POST", path, fmt.Sprintf(`{"user_id":%d}`, env.admin), env.bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("moderator adds moderator: got %d", rec.Code)
+	}
+
+	removeBob := fmt.Sprintf("%s/%d", path, env.bob)
+	if rec := env.do(t, "DELETE", removeBob, "", env.alice); rec.Code != http.StatusNoContent {
+		t.Fatalf("remove moderator: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := env.do(t, "DELETE", removeBob, "", env.admin); rec.Code != http.StatusNotFound {
+		t.Fatalf("remove missing moderator: got %d", rec.Code)
+	}
+	if rec := env.do(t, "PUT", forumPath, `{"title":"Bob's"}`, env.bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("update by former moderator: got %d", rec.Code)
+	}
+}
+
+func TestForumVisibility(t *testing.T) {
+	env := newTestEnv(t)
+
+	membersID, _ := env.store.Create(business.Forum{Title: "Club", Visibility: business.VisibilityMembers})
+	listPath := fmt.Sprintf("/api/forums/%d/messages-list", membersID)
+	if rec := env.do(t, "GET", listPath, "", 0); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous reads members forum: got %d", rec.Code)
+	}
+	if rec := env.do(t, "GET", listPath, "", env.bob); rec.Code != http.StatusOK {
+		t.Fatalf("member reads members forum: %d %q", rec.Code, rec.Body.String())
+	}
+	// Состав модераторов виден тем же, кому виден форум
+	modsPath := fmt.Sprintf("/api/forums/%d/moderators", membersID)
+	if rec := env.do(t, "GET", modsPath, "", 0); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous lists members forum moderators: got %d", rec.Code)
+	}
+	if rec := env.do(t, "GET", modsPath, "", env.bob); rec.Code != http.StatusOK {
+		t.Fatalf("member lists moderators: %d %q", rec.Code, rec.Body.String())
+	}
+	if err := env.store.UpdateUserRole(env.bob, "banned"); err != nil {
+		t.Fatal(err)
+	}
+	if rec := env.do(t, "GET", modsPath, "", env.bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("banned user lists moderators: got %d, want 403", rec.Code)
+	}
+	if err := env.store.UpdateUserRole(env.bob, "user"); err != nil {
+		t.Fatal(err)
+	}
+
+	archivedID, _ := env.store.Create(business.Forum{Title: "Old", Visibility: business.VisibilityArchived})
+	postPath := fmt.Sprintf("/api/forums/%d/messages", archivedID)
+	forumPath := fmt.Sprintf("/api/forums/%d", archivedID)
+	if rec := env.do(t, "POST", postPath, `{"content":"hi"}`, env.alice); rec.Code != http.StatusForbidden {
+		t.Fatalf("post to archived forum: got %d", rec.Code)
+	}
+	if rec := env.do(t, "PUT", forumPath, `{"visibility":"hidden"}`, env.admin); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown visibility: got %d", rec.Code)
+	}
+	if rec := env.do(t, "PUT", forumPath, `{"title":"Old","visibility":"public"}`, env.admin); rec.Code != http.StatusOK {
+		t.Fatalf("unarchive forum: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := env.do(t, "POST", postPath, `{"content":"hi"}`, env.alice); rec.Code != http.StatusCreated {
+		t.Fatalf("post after unarchive: %d %q", rec.Code, rec.Body.String())
+	}
+}
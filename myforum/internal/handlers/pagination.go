@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jaxxiy/myforum/internal/repository"
+)
+
+// parsePageRequest читает параметры after, before и limit.
+// Без курсора fromEnd выбирает последнюю страницу вместо первой.
+func parsePageRequest(r *http.Request, fromEnd bool) (repository.PageRequest, error) {
+	q := r.URL.Query()
+	page := repository.PageRequest{
+		Backward: fromEnd,
+		Limit:    repository.DefaultPageSize,
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return page, errors.New("limit must be a positive integer")
+		}
+		if limit > repository.MaxPageSize {
+			limit = repository.MaxPageSize
+		}
+		page.Limit = limit
+	}
+
+	after, before := q.Get("after"), q.Get("before")
+	if after != "" && before != "" {
+		return page, errors.New("after and before cannot be used together")
+	}
+	if after != "" {
+		cursor, err := repository.DecodeCursor(after)
+		if err != nil {
+			return page, err
+		}
+		page.Cursor = cursor
+		page.Backward = false
+	}
+	if before != "" {
+		cursor, err := repository.DecodeCursor(before)
+		if err != nil {
+			return page, err
+		}
+		page.Cursor = cursor
+		page.Backward = true
+	}
+	return page, nil
+}
+
+// pageLinks строит ссылки next/prev для страницы с первой и последней записью.
+// Пустая строка означает, что в эту сторону листать некуда.
+func pageLinks(r *http.Request, page repository.PageRequest, first, last *repository.Cursor, hasMore bool) (next, prev string) {
+	if first == nil || last == nil {
+		return "", ""
+	}
+
+	link := func(param string, c *repository.Cursor) string {
+		q := r.URL.Query()
+		q.Del("after")
+		q.Del("before")
+		q.Set(param, c.Encode())
+		q.Set("limit", strconv.Itoa(page.Limit))
+		return r.URL.Path + "?" + q.Encode()
+	}
+
+	// Курсор в запросе означает, что мы пришли с соседней страницы
+	if (!page.Backward && hasMore) || (page.Backward && page.Cursor != nil) {
+		next = link("after", last)
+	}
+	if (page.Backward && hasMore) || (!page.Backward && page.Cursor != nil) {
+		prev = link("before", first)
+	}
+	return next, prev
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/rbac"
+	"github.com/jaxxiy/myforum/internal/repository"
+)
+
+func registerProfileHandlers(r, api *mux.Router, repo repository.Store) {
+	r.HandleFunc("/users/{username}", UserProfilePage(repo)).Methods("GET")
+	api.HandleFunc("/users/{username}", GetUserProfile(repo)).Methods("GET")
+}
+
+// UserProfilePage — страница профиля; данные она загружает из /api/users/{username}
+func UserProfilePage(repo repository.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username := mux.Vars(r)["username"]
+		if _, err := repo.GetUserIDByUsername(username); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		renderTemplate(w, "user_profile.html", map[string]interface{}{"Username": username})
+	}
+}
+
+// GetUserProfile отдает публичный профиль и страницу последних сообщений
+// пользователя (параметры after, before, limit). Сообщения форумов для
+// участников видны только тем, кто может читать такие форумы.
+func GetUserProfile(repo repository.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		page, err := parsePageRequest(r, true)
+		if err != nil {
+			sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Форумы для участников видит тот, кто может их читать
+		withMembers := rbac.CanReadForum(userFromRequest(r, repo), &business.Forum{Visibility: business.VisibilityMembers})
+		profile, status, errMsg := userProfile(repo, mux.Vars(r)["username"], withMembers)
+		if status != 0 {
+			sendError(w, status, errMsg)
+			return
+		}
+		messages, hasMore, err := repo.GetUserMessagesPage(profile.ID, withMembers, page)
+		if err != nil {
+			sendError(w, http.StatusInternalServerError, "Failed to load messages")
+			return
+		}
+		if messages == nil {
+			messages = []business.Message{}
+		}
+
+		var first, last *repository.Cursor
+		if len(messages) > 0 {
+			first = &repository.Cursor{CreatedAt: messages[0].CreatedAt, ID: messages[0].ID}
+			last = &repository.Cursor{CreatedAt: messages[len(messages)-1].CreatedAt, ID: messages[len(messages)-1].ID}
+		}
+		next, prev := pageLinks(r, page, first, last, hasMore)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"user":     profile,
+			"messages": messages,
+			"next":     next,
+			"prev":     prev,
+		})
+	}
+}
+
+// userProfile собирает профиль username; withMembers учитывает в активности
+// форумы для участников
+func userProfile(repo repository.Store, username string, withMembers bool) (*business.UserProfile, int, string) {
+	id, err := repo.GetUserIDByUsername(username)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, http.StatusNotFound, "User not found"
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, "Failed to load user"
+	}
+	user, err := repo.GetUserByID(id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, "Failed to load user"
+	}
+
+	activity, err := repo.GetUserActivity(user.ID, withMembers)
+	if err != nil {
+		return nil, http.StatusInternalServerError, "Failed to load activity"
+	}
+	return &business.UserProfile{
+		ID:           user.ID,
+		Username:     user.Username,
+		Role:         user.Role,
+		Bio:          user.Bio,
+		AvatarURL:    user.AvatarURL,
+		JoinedAt:     user.CreatedAt,
+		UserActivity: activity,
+	}, 0, ""
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+type profileResponse struct {
+	User     business.UserProfile `json:"user"`
+	Messages []business.Message   `json:"messages"`
+	Next     string               `json:"next"`
+	Prev     string               `json:"prev"`
+}
+
+func TestUserProfile(t *testing.T) {
+	env := newTestEnv(t)
+	clubID, _ := env.store.Create(business.Forum{Title: "Club", Visibility: business.VisibilityMembers})
+	start := time.Now().Add(-time.Hour)
+	for i, text := range []string{"первое", "второе", "третье"} {
+		env.createMessage(t, env.alice, text, start.Add(time.Duration(i)*time.Minute))
+	}
+	env.createMessage(t, env.bob, "чужое", start)
+	env.store.CreateMessage(business.Message{ForumID: clubID, AuthorID: env.alice, Author: "alice", Content: "для своих", CreatedAt: start.Add(time.Hour)})
+	trashed := env.createMessage(t, env.alice, "удаленное", start)
+	env.store.DeleteMessage(trashed, env.admin)
+
+	profile := func(path string, userID int) profileResponse {
+		t.Helper()
+		rec := env.do(t, "GET", path, "", userID)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: %d %q", path, rec.Code, rec.Body.String())
+		}
+		if strings.Contains(rec.Body.String(), "alice@example.com") {
+			t.Fatal("profile exposes email")
+		}
+		var resp profileResponse
+		decode(t, rec, &resp)
+		return resp
+	}
+
+	// Аноним не видит форум для участников и сообщения из корзины
+	anon := profile("/api/users/alice", 0)
+	if anon.User.Username != "alice" || anon.User.Role != "user" || anon.User.JoinedAt.IsZero() {
+		t.Errorf("profile = %+v", anon.User)
+	}
+	if anon.User.MessageCount != 3 || len(anon.User.Forums) != 1 || anon.User.Forums[0].Title != "Golang" {
+		t.Errorf("anonymous activity = %+v", anon.User.UserActivity)
+	}
+	if len(anon.Messages) != 3 || anon.Messages[2].Content != "третье" {
+		t.Errorf("anonymous messages = %+v", anon.Messages)
+	}
+
+	member := profile("/api/users/alice", env.bob)
+	if member.User.MessageCount != 4 || len(member.User.Forums) != 2 || member.User.Forums[0].Title != "Club" {
+		t.Errorf("member activity = %+v", member.User.UserActivity)
+	}
+
+	// Страницы идут от последних сообщений к более ранним
+	last := profile("/api/users/alice?limit=2", 0)
+	if len(last.Messages) != 2 || last.Messages[1].Content != "третье" || last.Prev == "" {
+		t.Fatalf("last page = %+v", last)
+	}
+	earlier := profile(last.Prev, 0)
+	if len(earlier.Messages) != 1 || earlier.Messages[0].Content != "первое" || earlier.Prev != "" {
+		t.Errorf("earlier page = %+v", earlier)
+	}
+
+	if rec := env.do(t, "GET", "/api/users/nobody", "", 0); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown user: got %d", rec.Code)
+	}
+	if rec := env.do(t, "GET", "/users/alice", "", 0); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "alice") {
+		t.Errorf("profile page: got %d", rec.Code)
+	}
+	if rec := env.do(t, "GET", "/users/nobody", "", 0); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown profile page: got %d", rec.Code)
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/rbac"
+	"github.com/jaxxiy/myforum/internal/repository"
+)
+
+func registerRoleHandlers(api *mux.Router, repo repository.Store) {
+	api.HandleFunc("/admin/roles", ListRoles(repo)).Methods("GET")
+	api.HandleFunc("/admin/users/{id:[0-9]+}/role", AssignRole(repo)).Methods("PUT")
+}
+
+// authorize возвращает пользователя с правом perm или пишет 401/403 и возвращает nil
+func authorize(w http.ResponseWriter, r *http.Request, repo repository.Store, perm rbac.Permission) *business.User {
+	user := userFromRequest(r, repo)
+	if user == nil {
+		sendError(w, http.StatusUnauthorized, "Unauthorized")
+		return nil
+	}
+	if !rbac.Can(user, perm) {
+		sendError(w, http.StatusForbidden, "Forbidden")
+		return nil
+	}
+	return user
+}
+
+// currentUser — то, что страницы знают о вошедшем пользователе
+type currentUser struct {
+	ID          int
+	Username    string
+	Role        string
+	Permissions []rbac.Permission
+}
+
+// currentUserInfo описывает пользователя для шаблонов и JSON с учетом его прав
+// в форуме; для анонима поля пустые
+func currentUserInfo(user *business.User, access rbac.ForumAccess) currentUser {
+	if user == nil {
+		return currentUser{Permissions: []rbac.Permission{}}
+	}
+	return currentUser{
+		ID:          user.ID,
+		Username:    user.Username,
+		Role:        user.Role,
+		Permissions: rbac.PermissionsInForum(user, access),
+	}
+}
+
+type roleInfo struct {
+	Role        rbac.Role         `json:"role"`
+	Permissions []rbac.Permission `json:"permissions"`
+}
+
+// ListRoles отдает все роли с их правами
+func ListRoles(repo repository.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if authorize(w, r, repo, rbac.RoleAssign) == nil {
+			return
+		}
+
+		roles := make([]roleInfo, 0, len(rbac.Roles()))
+		for _, role := range rbac.Roles() {
+			roles = append(roles, roleInfo{Role: role, Permissions: rbac.Permissions(role)})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"roles": roles})
+	}
+}
+
+// AssignRole меняет роль пользователя. Свою роль менять нельзя, чтобы
+// последний администратор случайно не лишил себя прав.
+func AssignRole(repo repository.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		admin := authorize(w, r, repo, rbac.RoleAssign)
+		if admin == nil {
+			return
+		}
+
+		userID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			sendError(w, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+		if userID == admin.ID {
+			sendError(w, http.StatusBadRequest, "Cannot change your own role")
+			return
+		}
+
+		var req struct {
+			Role string `json:"role"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		role, err := rbac.ParseRole(req.Role)
+		if err != nil {
+			sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := repo.UpdateUserRole(userID, string(role)); err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				sendError(w, http.StatusNotFound, "User not found")
+				return
+			}
+			sendError(w, http.StatusInternalServerError, "Failed to update role")
+			return
+		}
+
+		user, err := repo.GetUserByID(userID)
+		if err != nil {
+			sendError(w, http.StatusInternalServerError, "Failed to load user")
+			return
+		}
+		json.NewEncoder(w).Encode(user)
+	}
+}
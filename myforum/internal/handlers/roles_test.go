@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAssignRole(t *testing.T) {
+	env := newTestEnv(t)
+	bobRole := fmt.Sprintf("/api/admin/users/%d/role", env.bob)
+
+	if rec := env.do(t, "GET", "/api/admin/roles", "", 0); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("roles without token: got %d, want 401", rec.Code)
+	}
+	if rec := env.do(t, "GET", "/api/admin/roles", "", env.alice); rec.Code != http.StatusForbidden {
+		t.Fatalf("roles as user: got %d, want 403", rec.Code)
+	}
+
+	rec := env.do(t, "GET", "/api/admin/roles", "", env.admin)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("roles as admin: got %d", rec.Code)
+	}
+	var roles struct {
+		Roles []struct {
+			Role        string   `json:"role"`
+			Permissions []string `json:"permissions"`
+		} `json:"roles"`
+	}
+	decode(t, rec, &roles)
+	if len(roles.Roles) != 5 || roles.Roles[0].Role != "admin" || len(roles.Roles[0].Permissions) == 0 {
+		t.Fatalf("roles = %+v", roles.Roles)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		path   string
+		body   string
+		userID int
+		want   int
+	}{
+		{"user assigns role", bobRole, `{"role":"admin"}`, env.alice, http.StatusForbidden},
+		{"unknown role", bobRole, `{"role":"root"}`, env.admin, http.StatusBadRequest},
+		{"own role", fmt.Sprintf("/api/admin/users/%d/role", env.admin), `{"role":"user"}`, env.admin, http.StatusBadRequest},
+		{"unknown user", "/api/admin/users/999/role", `{"role":"user"}`, env.admin, http.StatusNotFound},
+		{"promote to moderator", bobRole, `{"role":"moderator"}`, env.admin, http.StatusOK},
+	} {
+		if rec := env.do(t, "PUT", tt.path, tt.body, tt.userID); rec.Code != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+
+	bob, err := env.store.GetUserByID(env.bob)
+	if err != nil || bob.Role != "moderator" {
+		t.Fatalf("bob after promotion = %+v, %v", bob, err)
+	}
+}
+
+func TestRolePermissions(t *testing.T) {
+	env := newTestEnv(t)
+	msgID := env.createMessage(t, env.alice, "Привет", time.Now())
+	msgPath := fmt.Sprintf("/api/forums/%d/messages/%d", env.forumID, msgID)
+	postPath := fmt.Sprintf("/api/forums/%d/messages", env.forumID)
+
+	// Обычный пользователь не трогает чужие сообщения, модератор — может
+	if rec := env.do(t, "PUT", msgPath, `{"content":"bob"}`, env.bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("user edits foreign message: got %d, want 403", rec.Code)
+	}
+	if err := env.store.UpdateUserRole(env.bob, "moderator"); err != nil {
+		t.Fatal(err)
+	}
+	if rec := env.do(t, "PUT", msgPath, `{"content":"Исправлено модератором"}`, env.bob); rec.Code != http.StatusOK {
+		t.Fatalf("moderator edits foreign message: got %d, want 200", rec.Code)
+	}
+	// Писать от чужого имени может только администратор
+	if rec := env.do(t, "POST", postPath, fmt.Sprintf(`{"author_id":%d,"content":"Не я"}`, env.alice), env.bob); rec.Code != http.StatusForbidden {
+		t.Fatalf("moderator posts as alice: got %d, want 403", rec.Code)
+	}
+
+	// Read-only читает, но не пишет и не правит даже свое
+	if err := env.store.UpdateUserRole(env.alice, "read-only"); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name, method, path, body string
+	}{
+		{"post message", "POST", postPath, `{"content":"Можно?"}`},
+		{"edit own message", "PUT", msgPath, `{"content":"Мое"}`},
+		{"global chat", "POST", "/api/global-chat", `{"text":"Всем привет"}`},
+		{"create topic", "POST", fmt.Sprintf("/api/forums/%d/topics", env.forumID), `{"title":"Тема"}`},
+	} {
+		if rec := env.do(t, tt.method, tt.path, tt.body, env.alice); rec.Code != http.StatusForbidden {
+			t.Errorf("read-only %s: got %d, want 403", tt.name, rec.Code)
+		}
+	}
+
+	rec := env.do(t, "GET", fmt.Sprintf("/api/forums/%d/messages-list", env.forumID), "", env.alice)
+	var list struct {
+		CurrentRole        string   `json:"currentRole"`
+		CurrentPermissions []string `json:"currentPermissions"`
+	}
+	decode(t, rec, &list)
+	if list.CurrentRole != "read-only" || len(list.CurrentPermissions) != 0 {
+		t.Fatalf("read-only viewer = %+v", list)
+	}
+
+	rec = env.do(t, "GET", fmt.Sprintf("/api/forums/%d/messages-list", env.forumID), "", env.bob)
+	decode(t, rec, &list)
+	if !contains(list.CurrentPermissions, "message.delete.any") {
+		t.Fatalf("moderator permissions = %v", list.CurrentPermissions)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
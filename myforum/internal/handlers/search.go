@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/rbac"
+	"github.com/jaxxiy/myforum/internal/repository"
+)
+
+func RegisterSearchHandlers(r *mux.Router, search repository.SearchStore, users repository.UserReader) {
+	r.HandleFunc("/api/search", Search(search, users)).Methods("GET")
+}
+
+// Search — полнотекстовый поиск по форумам и сообщениям.
+// Параметры: q (обязателен), type (forum|message), forum_id, author,
+// from и to (YYYY-MM-DD или RFC3339, to включительно для дат), limit, offset.
+func Search(search repository.SearchStore, users repository.UserReader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		q := r.URL.Query()
+
+		params := repository.SearchParams{
+			Query:  strings.TrimSpace(q.Get("q")),
+			Type:   q.Get("type"),
+			Author: strings.TrimSpace(q.Get("author")),
+			Limit:  repository.DefaultPageSize,
+		}
+		if params.Query == "" {
+			sendError(w, http.StatusBadRequest, "q is required")
+			return
+		}
+		// Сообщения форумов для участников находятся тем, кому эти форумы видны:
+		// с действующим токеном и не заблокированным
+		params.WithMembers = rbac.CanReadForum(userFromRequest(r, users), &business.Forum{Visibility: business.VisibilityMembers})
+		if params.Type != "" && params.Type != business.SearchResultForum && params.Type != business.SearchResultMessage {
+			sendError(w, http.StatusBadRequest, "type must be forum or message")
+			return
+		}
+
+		var err error
+		if v := q.Get("forum_id"); v != "" {
+			if params.ForumID, err = strconv.Atoi(v); err != nil {
+				sendError(w, http.StatusBadRequest, "Invalid forum_id")
+				return
+			}
+		}
+		if v := q.Get("from"); v != "" {
+			if params.From, _, err = parseSearchDate(v); err != nil {
+				sendError(w, http.StatusBadRequest, "Invalid from date")
+				return
+			}
+		}
+		if v := q.Get("to"); v != "" {
+			var dateOnly bool
+			if params.To, dateOnly, err = parseSearchDate(v); err != nil {
+				sendError(w, http.StatusBadRequest, "Invalid to date")
+				return
+			}
+			// Дата без времени включает весь день
+			if dateOnly {
+				params.To = params.To.AddDate(0, 0, 1)
+			}
+		}
+		if v := q.Get("limit"); v != "" {
+			limit, err := strconv.Atoi(v)
+			if err != nil || limit < 1 {
+				sendError(w, http.StatusBadRequest, "limit must be a positive integer")
+				return
+			}
+			if limit > repository.MaxPageSize {
+				limit = repository.MaxPageSize
+			}
+			params.Limit = limit
+		}
+		if v := q.Get("offset"); v != "" {
+			if params.Offset, err = strconv.Atoi(v); err != nil || params.Offset < 0 {
+				sendError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+				return
+			}
+		}
+
+		results, hasMore, err := search.Search(params)
+		if err != nil {
+			sendError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if results == nil {
+			results = []business.SearchResult{}
+		}
+
+		link := func(offset int) string {
+			lq := r.URL.Query()
+			lq.Set("offset", strconv.Itoa(offset))
+			lq.Set("limit", strconv.Itoa(params.Limit))
+			return r.URL.Path + "?" + lq.Encode()
+		}
+		var next, prev string
+		if hasMore {
+			next = link(params.Offset + params.Limit)
+		}
+		if params.Offset > 0 {
+			prevOffset := params.Offset - params.Limit
+			if prevOffset < 0 {
+				prevOffset = 0
+			}
+			prev = link(prevOffset)
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"results": results,
+			"next":    next,
+			"prev":    prev,
+		})
+	}
+}
+
+// parseSearchDate принимает YYYY-MM-DD или RFC3339 и сообщает, была ли это дата без времени
+func parseSearchDate(v string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, false, err
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+type searchResponse struct {
+	Results []business.SearchResult `json:"results"`
+	Next    string                  `json:"next"`
+	Prev    string                  `json:"prev"`
+}
+
+func TestSearchValidation(t *testing.T) {
+	env := newTestEnv(t)
+	RegisterSearchHandlers(env.router, env.store, env.store)
+
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{"no query", "q=+", http.StatusBadRequest},
+		{"unknown type", "q=go&type=topic", http.StatusBadRequest},
+		{"invalid forum", "q=go&forum_id=go", http.StatusBadRequest},
+		{"invalid from", "q=go&from=yesterday", http.StatusBadRequest},
+		{"invalid to", "q=go&to=2024-13-01", http.StatusBadRequest},
+		{"zero limit", "q=go&limit=0", http.StatusBadRequest},
+		{"negative offset", "q=go&offset=-1", http.StatusBadRequest},
+		{"all filters", "q=go&type=message&forum_id=1&author=alice&from=2024-01-01&to=2024-01-01T12:00:00Z&limit=500&offset=0", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := env.do(t, "GET", "/api/search?"+tt.query, "", 0)
+			if rec.Code != tt.want {
+				t.Fatalf("got %d, want %d: %q", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	env := newTestEnv(t)
+	RegisterSearchHandlers(env.router, env.store, env.store)
+
+	yesterday := time.Now().AddDate(0, 0, -1)
+	older := env.createMessage(t, env.alice, "Каналы и горутины", yesterday)
+	newer := env.createMessage(t, env.bob, "Буферизованные каналы", time.Now())
+	clubID, _ := env.store.Create(business.Forum{Title: "Club", Visibility: business.VisibilityMembers})
+	club, _ := env.store.CreateMessage(business.Message{ForumID: clubID, AuthorID: env.alice, Author: "alice", Content: "Каналы для своих", CreatedAt: time.Now()})
+
+	search := func(query url.Values, userID int) searchResponse {
+		t.Helper()
+		rec := env.do(t, "GET", "/api/search?"+query.Encode(), "", userID)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("search %s: %d %q", query.Encode(), rec.Code, rec.Body.String())
+		}
+		var resp searchResponse
+		decode(t, rec, &resp)
+		return resp
+	}
+	ids := func(resp searchResponse) map[int]bool {
+		found := make(map[int]bool)
+		for _, r := range resp.Results {
+			if r.Type == business.SearchResultMessage {
+				found[r.ID] = true
+			}
+		}
+		return found
+	}
+
+	// Форумы для участников ищутся только с действующим токеном не заблокированного пользователя
+	q := url.Values{"q": {"каналы"}}
+	if found := ids(search(q, 0)); !found[older] || !found[newer] || found[club] {
+		t.Errorf("anonymous results = %v", found)
+	}
+	if found := ids(search(q, env.bob)); !found[club] {
+		t.Errorf("member results = %v, want club message %d", found, club)
+	}
+	if err := env.store.UpdateUserRole(env.bob, "banned"); err != nil {
+		t.Fatal(err)
+	}
+	if found := ids(search(q, env.bob)); found[club] {
+		t.Errorf("banned user found members-only message: %v", found)
+	}
+
+	// Дата без времени в to включает весь день
+	day := yesterday.UTC().Format("2006-01-02")
+	if found := ids(search(url.Values{"q": {"каналы"}, "from": {day}, "to": {day}}, 0)); len(found) != 1 || !found[older] {
+		t.Errorf("results for %s = %v, want only %d", day, found, older)
+	}
+	if found := ids(search(url.Values{"q": {"каналы"}, "author": {"bob"}}, 0)); len(found) != 1 || !found[newer] {
+		t.Errorf("results by bob = %v, want only %d", found, newer)
+	}
+
+	first := search(url.Values{"q": {"каналы"}, "type": {"message"}, "limit": {"1"}}, 0)
+	if len(first.Results) != 1 || first.Next == "" || first.Prev != "" {
+		t.Fatalf("first page = %+v", first)
+	}
+	if first.Results[0].Snippet != "Буферизованные <mark>каналы</mark>" {
+		t.Errorf("snippet = %q", first.Results[0].Snippet)
+	}
+	next, _ := url.Parse(first.Next)
+	second := search(next.Query(), 0)
+	if len(second.Results) != 1 || second.Results[0].ID == first.Results[0].ID || second.Next != "" || second.Prev == "" {
+		t.Errorf("second page = %+v", second)
+	}
+}
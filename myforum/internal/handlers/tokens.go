@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/repository"
+	"github.com/jaxxiy/myforum/internal/ws"
+	"github.com/jaxxiy/myforum/pkg/jwt"
+)
+
+// userFromRequest возвращает пользователя из заголовка Authorization или nil
+func userFromRequest(r *http.Request, repo repository.UserReader) *business.User {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil
+	}
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil
+	}
+	user, err := repo.GetUserByID(claims.UserID)
+	if err != nil {
+		return nil
+	}
+	return user
+}
+
+// parseToken проверяет подпись и срок JWT, а также что токен не отозван
+func parseToken(tokenString string) (*jwt.Claims, error) {
+	if revocations == nil {
+		return tokenManager.Parse(tokenString)
+	}
+	return tokenManager.ParseActive(tokenString, revocations)
+}
+
+// authenticateWS определяет пользователя по токену WebSocket-запроса. Без токена
+// соединение допускается только в анонимном режиме и тогда user == nil.
+// При отказе ответ уже записан и возвращается ok == false.
+func authenticateWS(w http.ResponseWriter, r *http.Request, repo repository.Store) (*business.User, bool) {
+	token := ws.TokenFromRequest(r)
+	if token == "" {
+		if allowAnonymousWS {
+			return nil, true
+		}
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	claims, err := parseToken(token)
+	if err != nil {
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return nil, false
+	}
+	user, err := repo.GetUserByID(claims.UserID)
+	if err != nil {
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return nil, false
+	}
+	return user, true
+}
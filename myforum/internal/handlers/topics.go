@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/events"
+	"github.com/jaxxiy/myforum/internal/rbac"
+	"github.com/jaxxiy/myforum/internal/repository"
+)
+
+func registerTopicHandlers(api *mux.Router, repo repository.Store, topics repository.TopicStore) {
+	api.HandleFunc("/forums/{id:[0-9]+}/topics", ListTopics(repo, topics)).Methods("GET")
+	api.HandleFunc("/forums/{id:[0-9]+}/topics", CreateTopic(repo, topics)).Methods("POST")
+
+	api.HandleFunc("/topics/{id:[0-9]+}", GetTopic(repo, topics)).Methods("GET")
+	api.HandleFunc("/topics/{id:[0-9]+}", UpdateTopic(repo, topics)).Methods("PUT")
+	api.HandleFunc("/topics/{id:[0-9]+}", DeleteTopic(repo, topics)).Methods("DELETE")
+
+	api.HandleFunc("/topics/{id:[0-9]+}/messages", GetTopicMessages(repo, topics)).Methods("GET")
+	api.HandleFunc("/topics/{id:[0-9]+}/messages", PostTopicMessage(repo, topics)).Methods("POST")
+}
+
+func ListTopics(repo repository.Store, topics repository.TopicStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		forumID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			sendError(w, http.StatusBadRequest, "Invalid forum ID")
+			return
+		}
+		if _, status, errMsg := forumForRead(repo, forumID, userFromRequest(r, repo)); status != 0 {
+			sendError(w, status, errMsg)
+			return
+		}
+
+		list, err := topics.GetByForum(forumID)
+		if err != nil {
+			sendError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if list == nil {
+			list = []business.Topic{}
+		}
+		json.NewEncoder(w).Encode(list)
+	}
+}
+
+func CreateTopic(repo repository.Store, topics repository.TopicStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		forumID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			sendError(w, http.StatusBadRequest, "Invalid forum ID")
+			return
+		}
+
+		user := authorize(w, r, repo, rbac.TopicCreate)
+		if user == nil {
+			return
+		}
+
+		var req struct {
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		if strings.TrimSpace(req.Title) == "" {
+			sendError(w, http.StatusBadRequest, "Title is required")
+			return
+		}
+
+		if _, status, errMsg := forumForWrite(repo, forumID, user); status != 0 {
+			sendError(w, status, errMsg)
+			return
+		}
+
+		topic := business.Topic{
+			ForumID:   forumID,
+			Title:     req.Title,
+			Desc:      req.Description,
+			CreatedAt: time.Now(),
+		}
+		id, err := topics.Create(topic)
+		if err != nil {
+			log.Printf("DB error: %v", err)
+			sendError(w, http.StatusInternalServerError, "Failed to create topic")
+			return
+		}
+		topic.ID = id
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(topic)
+	}
+}
+
+// GetTopic отдает страницу темы с ее сообщениями
+func GetTopic(repo repository.Store, topics repository.TopicStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			http.Error(w, "Некорректный ID", http.StatusBadRequest)
+			return
+		}
+
+		topic, err := topics.GetByID(id)
+		if err != nil {
+			http.Error(w, "Тема не найдена", http.StatusNotFound)
+			return
+		}
+		forum, err := repo.GetByID(topic.ForumID)
+		if err != nil {
+			http.Error(w, "Форум не найден", http.StatusNotFound)
+			return
+		}
+		// Сообщения форума для участников страница подгружает сама с токеном
+		var messages []business.Message
+		if rbac.CanReadForum(userFromRequest(r, repo), forum) {
+			messages, err = topics.GetMessages(id)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		renderTemplate(w, "topic_detail.html", map[string]interface{}{
+			"Forum":    forum,
+			"Topic":    topic,
+			"Messages": messages,
+		})
+	}
+}
+
+func UpdateTopic(repo repository.Store, topics repository.TopicStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			http.Error(w, "Invalid topic ID", http.StatusBadRequest)
+			return
+		}
+
+		topic, _, ok := loadTopicForManage(w, r, repo, topics, id, rbac.TopicUpdate)
+		if !ok {
+			return
+		}
+
+		var req struct {
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if strings.TrimSpace(req.Title) == "" {
+			http.Error(w, "Title is required", http.StatusBadRequest)
+			return
+		}
+
+		topic.Title = req.Title
+		topic.Desc = req.Description
+		if err := topics.Update(id, *topic); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(topic)
+	}
+}
+
+func DeleteTopic(repo repository.Store, topics repository.TopicStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			http.Error(w, "Invalid topic ID", http.StatusBadRequest)
+			return
+		}
+
+		topic, _, ok := loadTopicForManage(w, r, repo, topics, id, rbac.TopicDelete)
+		if !ok {
+			return
+		}
+		user := userFromRequest(r, repo)
+		// Тема по умолчанию хранит сообщения, созданные без темы
+		if topic.IsDefault {
+			http.Error(w, "Default topic cannot be deleted", http.StatusBadRequest)
+			return
+		}
+
+		// Сообщения темы уходят в корзину, а не удаляются
+		deleted, err := topics.Delete(id, user.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Открытые страницы и подписчики убирают их так же, как при DeleteMessage
+		for _, messageID := range deleted {
+			broadcastToForum(topic.ForumID, WSMessage{
+				Type: events.MessageDeleted,
+				Payload: map[string]interface{}{
+					"messageId": messageID,
+				},
+			})
+			eventBus.Publish(events.Event{Type: events.MessageDeleted, ForumID: topic.ForumID, MessageID: messageID})
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// loadTopicForManage загружает тему и проверяет, что у пользователя есть право perm
+// глобально или как у владельца либо модератора форума.
+// При ошибке ответ уже записан и возвращается ok == false.
+func loadTopicForManage(w http.ResponseWriter, r *http.Request, repo repository.Store, topics repository.TopicStore, id int, perm rbac.Permission) (*business.Topic, *business.Forum, bool) {
+	user := userFromRequest(r, repo)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, nil, false
+	}
+
+	topic, err := topics.GetByID(id)
+	if err != nil {
+		http.Error(w, "Topic not found", http.StatusNotFound)
+		return nil, nil, false
+	}
+	forum, err := repo.GetByID(topic.ForumID)
+	if err != nil {
+		http.Error(w, "Forum not found", http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	if !rbac.CanInForum(user, perm, forumAccess(repo, forum, user)) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return nil, nil, false
+	}
+	return topic, forum, true
+}
+
+func GetTopicMessages(repo repository.Store, topics repository.TopicStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			http.Error(w, "Invalid topic ID", http.StatusBadRequest)
+			return
+		}
+
+		topic, err := topics.GetByID(id)
+		if err != nil {
+			http.Error(w, "Topic not found", http.StatusNotFound)
+			return
+		}
+		user := userFromRequest(r, repo)
+		forum, status, errMsg := forumForRead(repo, topic.ForumID, user)
+		if status != 0 {
+			http.Error(w, errMsg, status)
+			return
+		}
+		messages, err := topics.GetMessages(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if messages == nil {
+			messages = []business.Message{}
+		}
+
+		current := currentUserInfo(user, forumAccess(repo, forum, user))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"topic":              topic,
+			"messages":           messages,
+			"currentUser":        current.Username,
+			"currentUserId":      current.ID,
+			"currentRole":        current.Role,
+			"currentPermissions": current.Permissions,
+		})
+	}
+}
+
+// PostTopicMessage создает сообщение в теме; автор берется из токена
+func PostTopicMessage(repo repository.Store, topics repository.TopicStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			sendError(w, http.StatusBadRequest, "Invalid topic ID")
+			return
+		}
+
+		user := authorize(w, r, repo, rbac.MessageCreateOwn)
+		if user == nil {
+			return
+		}
+
+		var req struct {
+			Content  string `json:"content"`
+			ParentID int    `json:"parent_id"`
+			QuoteID  int    `json:"quote_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendError(w, http.StatusBadRequest, "Invalid JSON format")
+			return
+		}
+		if strings.TrimSpace(req.Content) == "" {
+			sendError(w, http.StatusBadRequest, "Content is required")
+			return
+		}
+
+		topic, err := topics.GetByID(id)
+		if err != nil {
+			sendError(w, http.StatusNotFound, "Topic not found")
+			return
+		}
+		if _, status, errMsg := forumForWrite(repo, topic.ForumID, user); status != 0 {
+			sendError(w, status, errMsg)
+			return
+		}
+
+		msg := business.Message{
+			ForumID:   topic.ForumID,
+			TopicID:   topic.ID,
+			AuthorID:  user.ID,
+			Author:    user.Username,
+			Content:   req.Content,
+			CreatedAt: time.Now(),
+		}
+		if status, errMsg := resolveReplyAndQuote(repo, &msg, req.ParentID, req.QuoteID); status != 0 {
+			sendError(w, status, errMsg)
+			return
+		}
+
+		msgID, err := repo.CreateMessage(msg)
+		if err != nil {
+			log.Printf("DB error: %v", err)
+			sendError(w, http.StatusInternalServerError, "Failed to save message")
+			return
+		}
+		msg.ID = msgID
+
+		broadcastToForum(topic.ForumID, WSMessage{
+			Type:    events.MessageCreated,
+			Payload: msg,
+		})
+		eventBus.Publish(events.Event{Type: events.MessageCreated, ForumID: topic.ForumID, Message: &msg})
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(msg)
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jaxxiy/myforum/internal/rbac"
+	"github.com/jaxxiy/myforum/internal/repository"
+)
+
+func registerTrashHandlers(r *mux.Router, api *mux.Router, repo repository.Store) {
+	// Страница сама по себе пустая: данные она запрашивает с токеном из localStorage
+	r.HandleFunc("/admin/trash", TrashPage).Methods("GET")
+
+	api.HandleFunc("/admin/trash", ListTrash(repo)).Methods("GET")
+	api.HandleFunc("/admin/trash/forums/{id:[0-9]+}/restore", RestoreForum(repo)).Methods("POST")
+	api.HandleFunc("/admin/trash/messages/{id:[0-9]+}/restore", RestoreMessage(repo)).Methods("POST")
+}
+
+func TrashPage(w http.ResponseWriter, r *http.Request) {
+	renderTemplate(w, "trash.html", nil)
+}
+
+func ListTrash(repo repository.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if authorize(w, r, repo, rbac.TrashManage) == nil {
+			return
+		}
+
+		forums, err := repo.GetDeletedForums()
+		if err != nil {
+			sendError(w, http.StatusInternalServerError, "Failed to load deleted forums")
+			return
+		}
+		messages, err := repo.GetDeletedMessages()
+		if err != nil {
+			sendError(w, http.StatusInternalServerError, "Failed to load deleted messages")
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"forums":   forums,
+			"messages": messages,
+		})
+	}
+}
+
+func RestoreForum(repo repository.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if authorize(w, r, repo, rbac.TrashManage) == nil {
+			return
+		}
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		if err := repo.RestoreForum(id); err != nil {
+			if errors.Is(err, repository.ErrForumNotInTrash) {
+				sendError(w, http.StatusNotFound, "Forum not found in trash")
+				return
+			}
+			sendError(w, http.StatusInternalServerError, "Failed to restore forum")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func RestoreMessage(repo repository.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if authorize(w, r, repo, rbac.TrashManage) == nil {
+			return
+		}
+
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		if err := repo.RestoreMessage(id); err != nil {
+			if errors.Is(err, repository.ErrMessageNotInTrash) {
+				sendError(w, http.StatusNotFound, "Message not found in trash")
+				return
+			}
+			sendError(w, http.StatusInternalServerError, "Failed to restore message")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	env := newTestEnv(t)
+	msgID := env.createMessage(t, env.alice, "В корзину", time.Now())
+	keptID := env.createMessage(t, env.alice, "Остается", time.Now())
+	msgPath := fmt.Sprintf("/api/forums/%d/messages/%d", env.forumID, msgID)
+
+	if rec := env.do(t, "DELETE", msgPath, "", env.alice); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete message: got %d", rec.Code)
+	}
+	if messages, _ := env.store.GetMessages(env.forumID); len(messages) != 1 || messages[0].ID != keptID {
+		t.Fatalf("messages after soft delete: %+v", messages)
+	}
+
+	forumPath := fmt.Sprintf("/api/forums/%d", env.forumID)
+	if rec := env.do(t, "DELETE", forumPath, "", env.admin); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete forum: got %d", rec.Code)
+	}
+	if rec := env.do(t, "GET", forumPath, "", 0); rec.Code != http.StatusNotFound {
+		t.Fatalf("deleted forum page: got %d, want 404", rec.Code)
+	}
+	if _, err := env.store.GetMessageByID(keptID); err == nil {
+		t.Fatal("message of a deleted forum is still visible")
+	}
+
+	for _, tt := range []struct {
+		name   string
+		userID int
+		want   int
+	}{
+		{"anonymous", 0, http.StatusUnauthorized},
+		{"user", env.alice, http.StatusForbidden},
+		{"admin", env.admin, http.StatusOK},
+	} {
+		if rec := env.do(t, "GET", "/api/admin/trash", "", tt.userID); rec.Code != tt.want {
+			t.Errorf("trash for %s: got %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+
+	rec := env.do(t, "GET", "/api/admin/trash", "", env.admin)
+	var trash struct {
+		Forums   []business.Forum   `json:"forums"`
+		Messages []business.Message `json:"messages"`
+	}
+	decode(t, rec, &trash)
+	if len(trash.Forums) != 1 || trash.Forums[0].ID != env.forumID || trash.Forums[0].DeletedBy != env.admin {
+		t.Fatalf("deleted forums: %+v", trash.Forums)
+	}
+	if len(trash.Messages) != 1 || trash.Messages[0].ID != msgID || trash.Messages[0].DeletedBy != env.alice || trash.Messages[0].DeletedAt == nil {
+		t.Fatalf("deleted messages: %+v", trash.Messages)
+	}
+
+	restoreForum := fmt.Sprintf("/api/admin/trash/forums/%d/restore", env.forumID)
+	if rec := env.do(t, "POST", restoreForum, "", env.alice); rec.Code != http.StatusForbidden {
+		t.Fatalf("restore by user: got %d, want 403", rec.Code)
+	}
+	if rec := env.do(t, "POST", restoreForum, "", env.admin); rec.Code != http.StatusNoContent {
+		t.Fatalf("restore forum: got %d", rec.Code)
+	}
+	if rec := env.do(t, "POST", restoreForum, "", env.admin); rec.Code != http.StatusNotFound {
+		t.Fatalf("restore forum twice: got %d, want 404", rec.Code)
+	}
+	if messages, _ := env.store.GetMessages(env.forumID); len(messages) != 1 {
+		t.Fatalf("messages after forum restore: %+v", messages)
+	}
+
+	restoreMessage := fmt.Sprintf("/api/admin/trash/messages/%d/restore", msgID)
+	if rec := env.do(t, "POST", restoreMessage, "", env.admin); rec.Code != http.StatusNoContent {
+		t.Fatalf("restore message: got %d", rec.Code)
+	}
+	if messages, _ := env.store.GetMessages(env.forumID); len(messages) != 2 {
+		t.Fatalf("messages after message restore: %+v", messages)
+	}
+}
+
+func TestPurgeRemovesExpiredTrash(t *testing.T) {
+	env := newTestEnv(t)
+	msgID := env.createMessage(t, env.alice, "Удалить навсегда", time.Now())
+	if err := env.store.DeleteMessage(msgID, env.alice); err != nil {
+		t.Fatal(err)
+	}
+
+	// Срок не вышел: ничего не удаляется
+	if stats, err := env.store.Purge(time.Hour); err != nil || stats.Messages != 0 {
+		t.Fatalf("purge before retention: %+v, %v", stats, err)
+	}
+
+	forumID, _ := env.store.Create(business.Forum{Title: "Старый"})
+	env.store.CreateMessage(business.Message{ForumID: forumID, AuthorID: env.bob, Author: "bob", Content: "Тоже", CreatedAt: time.Now()})
+	if err := env.store.Delete(forumID, env.admin); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := env.store.Purge(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Forums != 1 || stats.Messages != 2 {
+		t.Fatalf("purge stats = %+v, want 1 forum and 2 messages", stats)
+	}
+	if forums, _ := env.store.GetDeletedForums(); len(forums) != 0 {
+		t.Fatalf("deleted forums after purge: %+v", forums)
+	}
+	if rec := env.do(t, "POST", fmt.Sprintf("/api/admin/trash/messages/%d/restore", msgID), "", env.admin); rec.Code != http.StatusNotFound {
+		t.Fatalf("restore purged message: got %d, want 404", rec.Code)
+	}
+}
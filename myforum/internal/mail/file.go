@@ -0,0 +1,37 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// File сохраняет каждое письмо в отдельный .eml в каталоге: для локальной
+// разработки без SMTP-сервера
+type File struct {
+	dir  string
+	from string
+	seq  atomic.Int64
+}
+
+func NewFile(dir, from string) (*File, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &File{dir: dir, from: from}, nil
+}
+
+func (f *File) Send(msg Message) error {
+	// Имя файла: время, порядковый номер и адрес, чтобы письма сортировались
+	name := fmt.Sprintf("%s-%d-%s.eml",
+		time.Now().Format("20060102T150405"), f.seq.Add(1),
+		strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(msg.To))
+	data, err := format(f.from, msg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(f.dir, name), data, 0o644)
+}
@@ -0,0 +1,91 @@
+// Package mail отправляет письма со ссылками на сервис авторизации:
+// подтверждение адреса и сброс пароля.
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/config"
+)
+
+// Message — текстовое письмо без вложений
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// New создает отправителя по настройкам mail.driver
+func New(cfg config.MailConfig) (Mailer, error) {
+	if cfg.Driver == "smtp" {
+		return NewSMTP(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From)
+	}
+	return NewFile(cfg.Dir, cfg.From)
+}
+
+// ErrInvalidAddress — адрес с переводом строки дописал бы в письмо свои заголовки
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// format собирает письмо в формате RFC 5322
+func format(from string, msg Message) ([]byte, error) {
+	if msg.To == "" || strings.ContainsAny(msg.To+from, "\r\n") {
+		return nil, ErrInvalidAddress
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(msg.Body)
+	b.WriteString("\r\n")
+	return b.Bytes(), nil
+}
+
+// Memory запоминает письма вместо отправки: для тестов
+type Memory struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// Sent возвращает отправленные письма по порядку
+func (m *Memory) Sent() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.sent...)
+}
+
+// Last возвращает последнее письмо на адрес to
+func (m *Memory) Last(to string) (Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.sent) - 1; i >= 0; i-- {
+		if m.sent[i].To == to {
+			return m.sent[i], true
+		}
+	}
+	return Message{}, false
+}
@@ -0,0 +1,39 @@
+package mail
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m, err := NewFile(dir, "forum@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send(Message{To: "alice@example.com", Subject: "Сброс пароля", Body: "ссылка"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*alice_at_example.com.eml"))
+	if len(files) != 1 {
+		t.Fatalf("mail files = %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	for _, want := range []string{"From: forum@example.com\r\n", "To: alice@example.com\r\n", "Subject: =?utf-8?q?", "\r\n\r\nссылка"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("mail has no %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestHeaderInjection(t *testing.T) {
+	m, _ := NewFile(t.TempDir(), "forum@example.com")
+	err := m.Send(Message{To: "alice@example.com\r\nBcc: eve@example.com", Subject: "hi"})
+	if !errors.Is(err, ErrInvalidAddress) {
+		t.Fatalf("got %v, want ErrInvalidAddress", err)
+	}
+}
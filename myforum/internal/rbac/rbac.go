@@ -0,0 +1,218 @@
+// Package rbac описывает роли пользователей и их права. Роль хранится в
+// users.role, набор прав каждой роли задан таблицей rolePermissions. Все
+// проверки доступа в HTTP-хендлерах и gRPC-сервисе идут через Can и CanOwned.
+package rbac
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+type Role string
+
+const (
+	Admin     Role = "admin"
+	Moderator Role = "moderator"
+	User      Role = "user"
+	ReadOnly  Role = "read-only" // читает, но ничего не пишет
+	Banned    Role = "banned"    // как read-only, но еще и не может войти
+)
+
+// DefaultRole получают новые пользователи
+const DefaultRole = User
+
+type Permission string
+
+const (
+	ForumCreate Permission = "forum.create"
+	ForumUpdate Permission = "forum.update"
+	ForumDelete Permission = "forum.delete"
+
+	TopicCreate Permission = "topic.create"
+	TopicUpdate Permission = "topic.update"
+	TopicDelete Permission = "topic.delete"
+
+	// .own — над своими сообщениями, .any — над любыми
+	MessageCreateOwn Permission = "message.create.own"
+	MessageCreateAny Permission = "message.create.any" // от имени другого автора
+	MessageUpdateOwn Permission = "message.update.own"
+	MessageUpdateAny Permission = "message.update.any"
+	MessageDeleteOwn Permission = "message.delete.own"
+	MessageDeleteAny Permission = "message.delete.any"
+
+	// Назначение модераторов отдельного форума
+	ForumModeratorsManage Permission = "forum.moderators.manage"
+
+	ChatPost    Permission = "chat.post"
+	TrashManage Permission = "trash.manage"
+	RoleAssign  Permission = "role.assign"
+	// Снятие блокировки входа после неудачных попыток
+	LoginUnlock Permission = "login.unlock"
+)
+
+// OwnedAction — действие, право на которое зависит от того, чей это объект
+type OwnedAction struct {
+	Name string
+	Own  Permission
+	Any  Permission
+}
+
+var (
+	MessageCreate = OwnedAction{"message.create", MessageCreateOwn, MessageCreateAny}
+	MessageUpdate = OwnedAction{"message.update", MessageUpdateOwn, MessageUpdateAny}
+	MessageDelete = OwnedAction{"message.delete", MessageDeleteOwn, MessageDeleteAny}
+)
+
+var userPermissions = []Permission{
+	ForumCreate,
+	TopicCreate,
+	MessageCreateOwn, MessageUpdateOwn, MessageDeleteOwn,
+	ChatPost,
+}
+
+var moderatorPermissions = append([]Permission{
+	ForumUpdate,
+	TopicUpdate, TopicDelete,
+	MessageUpdateAny, MessageDeleteAny,
+}, userPermissions...)
+
+var rolePermissions = map[Role][]Permission{
+	Admin: append([]Permission{
+		ForumDelete,
+		ForumModeratorsManage,
+		MessageCreateAny,
+		TrashManage,
+		RoleAssign,
+		LoginUnlock,
+	}, moderatorPermissions...),
+	Moderator: moderatorPermissions,
+	User:      userPermissions,
+	ReadOnly:  nil,
+	Banned:    nil,
+}
+
+// grants[роль][право] строится из rolePermissions один раз
+var grants = func() map[Role]map[Permission]bool {
+	m := make(map[Role]map[Permission]bool, len(rolePermissions))
+	for role, perms := range rolePermissions {
+		m[role] = make(map[Permission]bool, len(perms))
+		for _, p := range perms {
+			m[role][p] = true
+		}
+	}
+	return m
+}()
+
+// ForumAccess — кем пользователь приходится конкретному форуму
+type ForumAccess struct {
+	Owner     bool
+	Moderator bool
+}
+
+// Права, которые дает форум своему модератору и владельцу; действуют только в этом форуме
+var (
+	forumModeratorGrants = permissionSet(ForumUpdate, TopicUpdate, TopicDelete, MessageUpdateAny, MessageDeleteAny)
+	forumOwnerGrants     = permissionSet(ForumUpdate, ForumDelete, ForumModeratorsManage,
+		TopicUpdate, TopicDelete, MessageUpdateAny, MessageDeleteAny)
+)
+
+func permissionSet(perms ...Permission) map[Permission]bool {
+	set := make(map[Permission]bool, len(perms))
+	for _, p := range perms {
+		set[p] = true
+	}
+	return set
+}
+
+// Roles возвращает все роли от самой сильной к самой слабой
+func Roles() []Role {
+	return []Role{Admin, Moderator, User, ReadOnly, Banned}
+}
+
+// ParseRole проверяет, что строка — известная роль
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if _, ok := rolePermissions[role]; !ok {
+		return "", fmt.Errorf("unknown role %q", s)
+	}
+	return role, nil
+}
+
+// Permissions возвращает права роли по алфавиту; у неизвестной роли прав нет
+func Permissions(role Role) []Permission {
+	perms := make([]Permission, 0, len(grants[role]))
+	for p := range grants[role] {
+		perms = append(perms, p)
+	}
+	sort.Slice(perms, func(i, j int) bool { return perms[i] < perms[j] })
+	return perms
+}
+
+// IsPermission сообщает, что такое право есть в системе
+func IsPermission(p Permission) bool {
+	return grants[Admin][p]
+}
+
+// Can сообщает, есть ли у пользователя право. У анонимного (nil) прав нет.
+func Can(u *business.User, p Permission) bool {
+	return u != nil && grants[Role(u.Role)][p]
+}
+
+// CanInForum — как Can, но с учетом прав, которые дает сам форум. Пользователь
+// с ролью read-only или banned прав от форума не получает.
+func CanInForum(u *business.User, p Permission, access ForumAccess) bool {
+	if Can(u, p) {
+		return true
+	}
+	if u == nil || len(grants[Role(u.Role)]) == 0 {
+		return false
+	}
+	return access.Owner && forumOwnerGrants[p] || access.Moderator && forumModeratorGrants[p]
+}
+
+// PermissionsInForum возвращает права пользователя в форуме по алфавиту:
+// права роли вместе с правами, которые дает сам форум
+func PermissionsInForum(u *business.User, access ForumAccess) []Permission {
+	if u == nil {
+		return []Permission{}
+	}
+	perms := Permissions(Role(u.Role))
+	for p := range forumOwnerGrants {
+		if !grants[Role(u.Role)][p] && CanInForum(u, p, access) {
+			perms = append(perms, p)
+		}
+	}
+	sort.Slice(perms, func(i, j int) bool { return perms[i] < perms[j] })
+	return perms
+}
+
+// CanOwnedInForum — как CanOwned, но право .any может дать и сам форум
+func CanOwnedInForum(u *business.User, a OwnedAction, ownerID int, access ForumAccess) bool {
+	return CanInForum(u, a.Any, access) || CanOwned(u, a, ownerID)
+}
+
+// CanReadForum сообщает, виден ли форум пользователю: форум для участников
+// не видят анонимы и заблокированные
+func CanReadForum(u *business.User, f *business.Forum) bool {
+	if f.Visibility != business.VisibilityMembers {
+		return true
+	}
+	return u != nil && Role(u.Role) != Banned
+}
+
+// Writable сообщает, можно ли писать в форум: в архив не пишет никто
+func Writable(f *business.Forum) bool {
+	return f.Visibility != business.VisibilityArchived
+}
+
+// CanOwned проверяет действие над объектом, принадлежащим ownerID (ID автора):
+// нужно право .any или право .own, если объект свой. Объект с ownerID == 0
+// (автор удален) своим не бывает.
+func CanOwned(u *business.User, a OwnedAction, ownerID int) bool {
+	if Can(u, a.Any) {
+		return true
+	}
+	return u != nil && ownerID != 0 && u.ID == ownerID && Can(u, a.Own)
+}
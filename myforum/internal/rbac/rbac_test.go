@@ -0,0 +1,140 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+func TestCan(t *testing.T) {
+	user := func(name string, role Role) *business.User {
+		return &business.User{Username: name, Role: string(role)}
+	}
+
+	tests := []struct {
+		name string
+		user *business.User
+		perm Permission
+		want bool
+	}{
+		{"anonymous", nil, ChatPost, false},
+		{"user posts to chat", user("alice", User), ChatPost, true},
+		{"user cannot delete forums", user("alice", User), ForumDelete, false},
+		{"moderator updates forums", user("mod", Moderator), ForumUpdate, true},
+		{"moderator cannot manage trash", user("mod", Moderator), TrashManage, false},
+		{"admin assigns roles", user("root", Admin), RoleAssign, true},
+		{"read-only cannot post", user("ro", ReadOnly), ChatPost, false},
+		{"banned cannot post", user("spam", Banned), MessageCreateOwn, false},
+		{"unknown role has nothing", user("x", "superuser"), ChatPost, false},
+	}
+	for _, tt := range tests {
+		if got := Can(tt.user, tt.perm); got != tt.want {
+			t.Errorf("%s: Can = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCanOwned(t *testing.T) {
+	alice := &business.User{ID: 1, Username: "alice", Role: string(User)}
+	mod := &business.User{ID: 3, Username: "mod", Role: string(Moderator)}
+	ro := &business.User{ID: 4, Username: "ro", Role: string(ReadOnly)}
+	const bob = 2
+
+	if !CanOwned(alice, MessageDelete, alice.ID) {
+		t.Error("user cannot delete own message")
+	}
+	if CanOwned(alice, MessageDelete, bob) {
+		t.Error("user can delete someone else's message")
+	}
+	if !CanOwned(mod, MessageDelete, bob) {
+		t.Error("moderator cannot delete someone else's message")
+	}
+	if CanOwned(mod, MessageCreate, bob) {
+		t.Error("moderator can post on behalf of another user")
+	}
+	if CanOwned(ro, MessageUpdate, ro.ID) {
+		t.Error("read-only user can edit own message")
+	}
+	if CanOwned(nil, MessageUpdate, 0) {
+		t.Error("anonymous user can edit a message without author")
+	}
+	if CanOwned(&business.User{Username: "ghost", Role: string(User)}, MessageUpdate, 0) {
+		t.Error("user without ID owns a message of a deleted author")
+	}
+}
+
+func TestCanInForum(t *testing.T) {
+	alice := &business.User{ID: 1, Username: "alice", Role: string(User)}
+	ro := &business.User{ID: 4, Username: "ro", Role: string(ReadOnly)}
+	const bob = 2
+	owner := ForumAccess{Owner: true}
+	moderator := ForumAccess{Moderator: true}
+
+	if CanInForum(alice, ForumUpdate, ForumAccess{}) {
+		t.Error("user can update a forum they do not own")
+	}
+	if !CanInForum(alice, ForumDelete, owner) {
+		t.Error("owner cannot delete own forum")
+	}
+	if !CanInForum(alice, ForumModeratorsManage, owner) {
+		t.Error("owner cannot manage forum moderators")
+	}
+	if !CanOwnedInForum(alice, MessageDelete, bob, moderator) {
+		t.Error("forum moderator cannot delete someone else's message")
+	}
+	if CanInForum(alice, ForumDelete, moderator) || CanInForum(alice, ForumModeratorsManage, moderator) {
+		t.Error("forum moderator can delete the forum or manage moderators")
+	}
+	if CanInForum(ro, ForumUpdate, owner) || CanOwnedInForum(ro, MessageDelete, bob, moderator) {
+		t.Error("read-only user got permissions from the forum")
+	}
+	if CanInForum(nil, ForumUpdate, owner) {
+		t.Error("anonymous user got permissions from the forum")
+	}
+	if perms := PermissionsInForum(alice, moderator); !containsPermission(perms, MessageUpdateAny) {
+		t.Errorf("forum moderator permissions = %v, want %s", perms, MessageUpdateAny)
+	}
+}
+
+func TestForumVisibility(t *testing.T) {
+	alice := &business.User{Username: "alice", Role: string(User)}
+	banned := &business.User{Username: "spam", Role: string(Banned)}
+	members := &business.Forum{Visibility: business.VisibilityMembers}
+	archived := &business.Forum{Visibility: business.VisibilityArchived}
+
+	if CanReadForum(nil, members) || CanReadForum(banned, members) {
+		t.Error("members-only forum is readable by anonymous or banned user")
+	}
+	if !CanReadForum(alice, members) || !CanReadForum(nil, archived) {
+		t.Error("forum is not readable when it should be")
+	}
+	if Writable(archived) || !Writable(members) {
+		t.Error("Writable ignores archived visibility")
+	}
+}
+
+func containsPermission(perms []Permission, p Permission) bool {
+	for _, perm := range perms {
+		if perm == p {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRolesAreConsistent(t *testing.T) {
+	for _, role := range Roles() {
+		if _, err := ParseRole(string(role)); err != nil {
+			t.Errorf("role %s from Roles() is not parseable: %v", role, err)
+		}
+		// Администратор может все, что может любая другая роль
+		for _, p := range Permissions(role) {
+			if !IsPermission(p) {
+				t.Errorf("admin lacks %s granted to %s", p, role)
+			}
+		}
+	}
+	if _, err := ParseRole("root"); err == nil {
+		t.Error("ParseRole accepted unknown role")
+	}
+}
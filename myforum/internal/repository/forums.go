@@ -19,19 +19,28 @@ func NewForumsRepo(db *sql.DB) *ForumsRepo {
 	}
 }
 
+// forumColumns — колонки форума в порядке, который ожидает scanForum
+const forumColumns = `id, name, description, COALESCE(owner_id, 0), visibility, created_at`
+
+func scanForum(row rowScanner) (business.Forum, error) {
+	var f business.Forum
+	err := row.Scan(&f.ID, &f.Title, &f.Description, &f.OwnerID, &f.Visibility, &f.CreatedAt)
+	return f, err
+}
+
 func (r *ForumsRepo) Create(f business.Forum) (int, error) {
 	var id int
 	// Явно указываем, что created_at должен использовать значение по умолчанию
 	err := r.DB.QueryRow(`
-        INSERT INTO forums (name, description, created_at)
-        VALUES ($1, $2, DEFAULT)
+        INSERT INTO forums (name, description, owner_id, visibility, created_at)
+        VALUES ($1, $2, NULLIF($3, 0), COALESCE(NULLIF($4, ''), 'public'), DEFAULT)
         RETURNING id`,
-		f.Title, f.Description).Scan(&id)
+		f.Title, f.Description, f.OwnerID, f.Visibility).Scan(&id)
 	return id, err
 }
 
 func (r *ForumsRepo) GetAll() ([]business.Forum, error) {
-	rows, err := r.DB.Query(`SELECT id, name, description, created_at FROM forums`)
+	rows, err := r.DB.Query(`SELECT ` + forumColumns + ` FROM forums WHERE deleted_at IS NULL`)
 	if err != nil {
 		return nil, err
 	}
@@ -39,8 +48,8 @@ func (r *ForumsRepo) GetAll() ([]business.Forum, error) {
 
 	var forums []business.Forum
 	for rows.Next() {
-		var f business.Forum
-		if err := rows.Scan(&f.ID, &f.Title, &f.Description, &f.CreatedAt); err != nil {
+		f, err := scanForum(rows)
+		if err != nil {
 			return nil, err
 		}
 		forums = append(forums, f)
@@ -48,12 +57,36 @@ func (r *ForumsRepo) GetAll() ([]business.Forum, error) {
 	return forums, nil
 }
 
+// GetAllPage возвращает страницу форумов и признак того, что в направлении
+// листания есть еще записи
+func (r *ForumsRepo) GetAllPage(p PageRequest) ([]business.Forum, bool, error) {
+	tail, args := p.keyset("deleted_at IS NULL", nil)
+	rows, err := r.DB.Query(`SELECT `+forumColumns+` FROM forums`+tail, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var forums []business.Forum
+	for rows.Next() {
+		f, err := scanForum(rows)
+		if err != nil {
+			return nil, false, err
+		}
+		forums = append(forums, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	forums, hasMore := trimPage(forums, p)
+	return forums, hasMore, nil
+}
+
 func (r *ForumsRepo) GetByID(id int) (*business.Forum, error) {
-	query := `SELECT id, name, description FROM forums WHERE id = $1`
-	row := r.DB.QueryRow(query, id)
+	query := `SELECT ` + forumColumns + ` FROM forums WHERE id = $1 AND deleted_at IS NULL`
 
-	var forum business.Forum
-	err := row.Scan(&forum.ID, &forum.Title, &forum.Description)
+	forum, err := scanForum(r.DB.QueryRow(query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errors.New("forum not found")
@@ -64,11 +97,13 @@ func (r *ForumsRepo) GetByID(id int) (*business.Forum, error) {
 	return &forum, nil
 }
 
-// Аналогичные методы для Update и Delete
+// Update меняет название, описание и видимость; пустая видимость не меняется.
+// Владелец форума не меняется никогда.
 func (r *ForumsRepo) Update(id int, f business.Forum) error {
 	result, err := r.DB.Exec(
-		`UPDATE forums SET name = $1, description = $2 WHERE id = $3`,
-		f.Title, f.Description, id,
+		`UPDATE forums SET name = $1, description = $2, visibility = COALESCE(NULLIF($4, ''), visibility)
+		WHERE id = $3 AND deleted_at IS NULL`,
+		f.Title, f.Description, id, f.Visibility,
 	)
 	if err != nil {
 		return err
@@ -81,11 +116,11 @@ func (r *ForumsRepo) Update(id int, f business.Forum) error {
 	return nil
 }
 
-// Delete (новый метод)
-func (r *ForumsRepo) Delete(id int) error {
+// Delete переносит форум в корзину вместе со всеми его сообщениями
+func (r *ForumsRepo) Delete(id int, deletedBy int) error {
 	result, err := r.DB.Exec(
-		`DELETE FROM forums WHERE id = $1`,
-		id,
+		`UPDATE forums SET deleted_at = NOW(), deleted_by = NULLIF($2, 0) WHERE id = $1 AND deleted_at IS NULL`,
+		id, deletedBy,
 	)
 	if err != nil {
 		return err
@@ -100,11 +135,44 @@ func (r *ForumsRepo) Delete(id int) error {
 
 //Сообщения
 
+// messagesFrom — сообщения с текущим именем автора из users. Имя из
+// messages.author остается только у сообщений без author_id. Подзапрос
+// называется messages, поэтому условия пишутся как для самой таблицы.
+const messagesFrom = `(SELECT m.*, COALESCE(u.username, m.author) AS author_name
+	FROM messages m LEFT JOIN users u ON u.id = m.author_id) messages`
+
+// messageColumns — колонки сообщения из messagesFrom в порядке, который ожидает scanMessage
+const messageColumns = `id, forum_id, COALESCE(topic_id, 0), COALESCE(parent_id, 0), COALESCE(author_id, 0), author_name, content,
+	COALESCE(quote_message_id, 0), COALESCE(quote_author, ''), COALESCE(quote_content, ''), created_at, edited_at,
+	deleted_at, COALESCE(deleted_by, 0)`
+
+// liveMessage — условие для сообщений вне корзины, в том числе вне удаленного форума
+const liveMessage = `deleted_at IS NULL AND forum_id IN (SELECT id FROM forums WHERE deleted_at IS NULL)`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMessage(row rowScanner) (*business.Message, error) {
+	var m business.Message
+	var quote business.MessageQuote
+	err := row.Scan(&m.ID, &m.ForumID, &m.TopicID, &m.ParentID, &m.AuthorID, &m.Author, &m.Content,
+		&quote.MessageID, &quote.Author, &quote.Content, &m.CreatedAt, &m.EditedAt,
+		&m.DeletedAt, &m.DeletedBy)
+	if err != nil {
+		return nil, err
+	}
+	if quote.MessageID != 0 {
+		m.Quote = &quote
+	}
+	return &m, nil
+}
+
 func (r *ForumsRepo) CreateMessage(msg business.Message) (int, error) {
 	// 1. Проверяем существование форума (исправленный запрос)
 	var exists bool
 	fmt.Println(msg.ForumID)
-	err := r.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM forums WHERE id = $1)", msg.ForumID).Scan(&exists)
+	err := r.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM forums WHERE id = $1 AND deleted_at IS NULL)", msg.ForumID).Scan(&exists)
 	if err != nil {
 		return 0, fmt.Errorf("forum check failed: %v", err)
 	}
@@ -112,11 +180,29 @@ func (r *ForumsRepo) CreateMessage(msg business.Message) (int, error) {
 		return 0, fmt.Errorf("forum with ID %d not found", msg.ForumID)
 	}
 
-	// 2. Вставляем сообщение (исправленный запрос)
+	// 2. Без явной темы сообщение попадает в тему форума по умолчанию
+	if msg.TopicID == 0 {
+		msg.TopicID, err = defaultTopicID(r.DB, msg.ForumID)
+		if err != nil {
+			return 0, fmt.Errorf("default topic lookup failed: %v", err)
+		}
+	}
+
+	// 3. Вставляем сообщение (исправленный запрос)
+	var quoteID sql.NullInt64
+	var quoteAuthor, quoteContent sql.NullString
+	if msg.Quote != nil {
+		quoteID = sql.NullInt64{Int64: int64(msg.Quote.MessageID), Valid: true}
+		quoteAuthor = sql.NullString{String: msg.Quote.Author, Valid: true}
+		quoteContent = sql.NullString{String: msg.Quote.Content, Valid: true}
+	}
+
 	var id int
-	err = r.DB.QueryRow(
-		"INSERT INTO messages (forum_id, author, content, created_at) VALUES ($1, $2, $3, $4) RETURNING id",
-		msg.ForumID, msg.Author, msg.Content, msg.CreatedAt,
+	err = r.DB.QueryRow(`
+		INSERT INTO messages (forum_id, topic_id, parent_id, author_id, author, content, quote_message_id, quote_author, quote_content, created_at)
+		VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0), $5, $6, $7, $8, $9, $10)
+		RETURNING id`,
+		msg.ForumID, msg.TopicID, msg.ParentID, msg.AuthorID, msg.Author, msg.Content, quoteID, quoteAuthor, quoteContent, msg.CreatedAt,
 	).Scan(&id)
 
 	if err != nil {
@@ -128,9 +214,9 @@ func (r *ForumsRepo) CreateMessage(msg business.Message) (int, error) {
 
 func (r *ForumsRepo) GetMessages(forumID int) ([]business.Message, error) {
 	rows, err := r.DB.Query(`
-		SELECT id, forum_id, author, content, created_at 
-		FROM messages 
-		WHERE forum_id = $1 
+		SELECT `+messageColumns+`
+		FROM `+messagesFrom+`
+		WHERE forum_id = $1 AND `+liveMessage+`
 		ORDER BY created_at`, forumID)
 	if err != nil {
 		return nil, err
@@ -139,54 +225,142 @@ func (r *ForumsRepo) GetMessages(forumID int) ([]business.Message, error) {
 
 	var messages []business.Message
 	for rows.Next() {
-		var m business.Message
-		if err := rows.Scan(&m.ID, &m.ForumID, &m.Author, &m.Content, &m.CreatedAt); err != nil {
+		m, err := scanMessage(rows)
+		if err != nil {
 			return nil, err
 		}
-		messages = append(messages, m)
+		messages = append(messages, *m)
 	}
 	return messages, nil
 }
 
-// DeleteMessage удаляет сообщение по ID
-func (r *ForumsRepo) DeleteMessage(id int) error {
-	_, err := r.DB.Exec("DELETE FROM messages WHERE id = $1", id)
+// GetMessagesPage возвращает страницу сообщений форума по (created_at, id)
+func (r *ForumsRepo) GetMessagesPage(forumID int, p PageRequest) ([]business.Message, bool, error) {
+	tail, args := p.keyset("forum_id = $1 AND "+liveMessage, []interface{}{forumID})
+	rows, err := r.DB.Query(`SELECT `+messageColumns+` FROM `+messagesFrom+tail, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var messages []business.Message
+	for rows.Next() {
+		m, err := scanMessage(rows)
+		if err != nil {
+			return nil, false, err
+		}
+		messages = append(messages, *m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	messages, hasMore := trimPage(messages, p)
+	return messages, hasMore, nil
+}
+
+// DeleteMessage переносит сообщение в корзину
+func (r *ForumsRepo) DeleteMessage(id int, deletedBy int) error {
+	_, err := r.DB.Exec(
+		"UPDATE messages SET deleted_at = NOW(), deleted_by = NULLIF($2, 0) WHERE id = $1 AND deleted_at IS NULL",
+		id, deletedBy,
+	)
 	return err
 }
 
-func (r *ForumsRepo) PutMessage(messageID int, updatedContent string) (*business.Message, error) {
-	var updatedMessage business.Message
+func (r *ForumsRepo) PutMessage(messageID int, updatedContent string, editorID int) (*business.Message, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to update message: %w", err)
+	}
+	defer tx.Rollback()
 
-	// Выполняем SQL-запрос для обновления сообщения
-	err := r.DB.QueryRow(`
+	// Блокируем строку, чтобы параллельная правка не потеряла ревизию
+	var previous string
+	err = tx.QueryRow(`SELECT content FROM messages WHERE id = $1 AND `+liveMessage+` FOR UPDATE`, messageID).Scan(&previous)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update message: %w", err)
+	}
+
+	// NOW() постоянен в транзакции: время ревизии совпадает с edited_at
+	_, err = tx.Exec(`
+		INSERT INTO message_revisions (message_id, content, editor_id, edited_at)
+		VALUES ($1, $2, NULLIF($3, 0), NOW())`,
+		messageID, previous, editorID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save revision: %w", err)
+	}
+
+	_, err = tx.Exec(`
         UPDATE messages 
-        SET content = $1
-        WHERE id = $2
-        RETURNING id, forum_id, author, content, created_at`,
+        SET content = $1, edited_at = NOW()
+        WHERE id = $2`,
 		updatedContent,
 		messageID,
-	).Scan(
-		&updatedMessage.ID,
-		&updatedMessage.ForumID,
-		&updatedMessage.Author,
-		&updatedMessage.Content,
-		&updatedMessage.CreatedAt,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update message: %w", err)
+	}
 
+	// Имя автора есть только в messagesFrom, поэтому перечитываем сообщение
+	updatedMessage, err := scanMessage(tx.QueryRow(
+		"SELECT "+messageColumns+" FROM "+messagesFrom+" WHERE id = $1", messageID,
+	))
 	if err != nil {
 		return nil, fmt.Errorf("failed to update message: %w", err)
 	}
 
-	return &updatedMessage, nil
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update message: %w", err)
+	}
+	return updatedMessage, nil
+}
+
+// GetMessageRevisions возвращает прежние версии сообщения от старых к новым
+func (r *ForumsRepo) GetMessageRevisions(messageID int) ([]business.MessageRevision, error) {
+	rows, err := r.DB.Query(`
+		SELECT mr.id, mr.message_id, mr.content, COALESCE(mr.editor_id, 0), COALESCE(u.username, ''), mr.edited_at
+		FROM message_revisions mr
+		LEFT JOIN users u ON u.id = mr.editor_id
+		WHERE mr.message_id = $1
+		ORDER BY mr.edited_at, mr.id`, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []business.MessageRevision{}
+	for rows.Next() {
+		var rev business.MessageRevision
+		if err := rows.Scan(&rev.ID, &rev.MessageID, &rev.Content, &rev.EditorID, &rev.Editor, &rev.EditedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// chatMessagesFrom — мини-чат с текущими именами авторов, как messagesFrom
+const chatMessagesFrom = `(SELECT c.*, COALESCE(u.username, c.author) AS author_name
+	FROM chat_messages c LEFT JOIN users u ON u.id = c.author_id) chat_messages`
+
+// chatColumns — колонки сообщения мини-чата в порядке, который ожидает scanGlobalMessage
+const chatColumns = `id, COALESCE(author_id, 0), author_name, message, created_at`
+
+func scanGlobalMessage(row rowScanner) (business.GlobalMessage, error) {
+	var m business.GlobalMessage
+	err := row.Scan(&m.ID, &m.AuthorID, &m.Author, &m.Content, &m.CreatedAt)
+	return m, err
 }
 
 func (r *ForumsRepo) CreateGlobalMessage(msg business.GlobalMessage) (int, error) {
 	var id int
 	err := r.DB.QueryRow(`
-		INSERT INTO chat_messages (author, message, created_at) 
-		VALUES ($1, $2, $3) 
+		INSERT INTO chat_messages (author_id, author, message, created_at) 
+		VALUES (NULLIF($1, 0), $2, $3, $4) 
 		RETURNING id`,
-		msg.Author, msg.Content, msg.CreatedAt,
+		msg.AuthorID, msg.Author, msg.Content, msg.CreatedAt,
 	).Scan(&id)
 
 	if err != nil {
@@ -199,8 +373,8 @@ func (r *ForumsRepo) CreateGlobalMessage(msg business.GlobalMessage) (int, error
 // GetGlobalMessages возвращает последние сообщения из мини-чата
 func (r *ForumsRepo) GetGlobalMessages(limit int) ([]business.GlobalMessage, error) {
 	rows, err := r.DB.Query(`
-		SELECT id, author, message, created_at
-		FROM chat_messages
+		SELECT `+chatColumns+`
+		FROM `+chatMessagesFrom+`
 		ORDER BY created_at DESC
 		LIMIT $1`, limit)
 	if err != nil {
@@ -210,8 +384,8 @@ func (r *ForumsRepo) GetGlobalMessages(limit int) ([]business.GlobalMessage, err
 
 	var messages []business.GlobalMessage
 	for rows.Next() {
-		var m business.GlobalMessage
-		if err := rows.Scan(&m.ID, &m.Author, &m.Content, &m.CreatedAt); err != nil {
+		m, err := scanGlobalMessage(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan global message: %w", err)
 		}
 		messages = append(messages, m)
@@ -231,8 +405,8 @@ func (r *ForumsRepo) DeleteGlobalMessage(id int) error {
 
 func (r *ForumsRepo) GetGlobalChatHistory(limit int) ([]business.GlobalMessage, error) {
 	rows, err := r.DB.Query(`
-        SELECT id, author, message, created_at 
-        FROM chat_messages
+        SELECT `+chatColumns+`
+        FROM `+chatMessagesFrom+`
         ORDER BY created_at ASC 
         LIMIT $1`, limit)
 	if err != nil {
@@ -242,8 +416,7 @@ func (r *ForumsRepo) GetGlobalChatHistory(limit int) ([]business.GlobalMessage,
 
 	var history []business.GlobalMessage
 	for rows.Next() {
-		var msg business.GlobalMessage
-		err := rows.Scan(&msg.ID, &msg.Author, &msg.Content, &msg.CreatedAt)
+		msg, err := scanGlobalMessage(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -253,9 +426,34 @@ func (r *ForumsRepo) GetGlobalChatHistory(limit int) ([]business.GlobalMessage,
 	return history, nil
 }
 
+// GetGlobalChatPage возвращает страницу истории мини-чата по (created_at, id)
+func (r *ForumsRepo) GetGlobalChatPage(p PageRequest) ([]business.GlobalMessage, bool, error) {
+	tail, args := p.keyset("", nil)
+	rows, err := r.DB.Query(`SELECT `+chatColumns+` FROM `+chatMessagesFrom+tail, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get global chat page: %w", err)
+	}
+	defer rows.Close()
+
+	var history []business.GlobalMessage
+	for rows.Next() {
+		msg, err := scanGlobalMessage(rows)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to scan global message: %w", err)
+		}
+		history = append(history, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	history, hasMore := trimPage(history, p)
+	return history, hasMore, nil
+}
+
 func (r *ForumsRepo) GetUserByID(userID int) (*business.User, error) {
 	query := `
-        SELECT id, username, email, created_at, updated_at, role
+        SELECT id, username, email, created_at, updated_at, role, email_verified_at, bio, avatar_url
         FROM users
         WHERE id = $1`
 
@@ -267,10 +465,13 @@ func (r *ForumsRepo) GetUserByID(userID int) (*business.User, error) {
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&user.Role,
+		&user.EmailVerifiedAt,
+		&user.Bio,
+		&user.AvatarURL,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 	if err != nil {
 		return nil, err
@@ -279,14 +480,27 @@ func (r *ForumsRepo) GetUserByID(userID int) (*business.User, error) {
 	return user, nil
 }
 
-func (r *ForumsRepo) GetMessageByID(messageID int) (*business.Message, error) {
-	var m business.Message
-	err := r.DB.QueryRow(
-		"SELECT id, forum_id, author, content, created_at FROM messages WHERE id = $1",
-		messageID,
-	).Scan(&m.ID, &m.ForumID, &m.Author, &m.Content, &m.CreatedAt)
+func (r *ForumsRepo) UpdateUserRole(userID int, role string) error {
+	result, err := r.DB.Exec(
+		"UPDATE users SET role = $1, updated_at = NOW() WHERE id = $2",
+		role, userID,
+	)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return &m, nil
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *ForumsRepo) GetMessageByID(messageID int) (*business.Message, error) {
+	return scanMessage(r.DB.QueryRow(
+		"SELECT "+messageColumns+" FROM "+messagesFrom+" WHERE id = $1 AND "+liveMessage,
+		messageID,
+	))
 }
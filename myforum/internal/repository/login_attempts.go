@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+// LoginAttemptRepo хранит счетчики неудачных входов в Postgres, чтобы их
+// видели все экземпляры сервиса авторизации
+type LoginAttemptRepo struct {
+	db *sql.DB
+}
+
+var _ LoginAttemptStore = (*LoginAttemptRepo)(nil)
+
+func NewLoginAttemptRepo(db *sql.DB) *LoginAttemptRepo {
+	return &LoginAttemptRepo{db: db}
+}
+
+func (r *LoginAttemptRepo) GetLoginAttempts(scope, key string, at time.Time) (business.LoginAttempts, error) {
+	a := business.LoginAttempts{Scope: scope, Key: key}
+	err := r.db.QueryRow(`
+		SELECT failures, last_failure_at, locked_until
+		FROM login_attempts
+		WHERE scope = $1 AND key = $2 AND expires_at > $3`, scope, key, at,
+	).Scan(&a.Failures, &a.LastFailureAt, &a.LockedUntil)
+	if err == sql.ErrNoRows {
+		return a, nil
+	}
+	return a, err
+}
+
+// RecordLoginFailure увеличивает счетчик одним запросом, поэтому параллельные
+// попытки не теряются
+func (r *LoginAttemptRepo) RecordLoginFailure(scope, key string, at time.Time, window time.Duration) (business.LoginAttempts, error) {
+	a := business.LoginAttempts{Scope: scope, Key: key}
+	err := r.db.QueryRow(`
+		INSERT INTO login_attempts AS a (scope, key, failures, last_failure_at, expires_at)
+		VALUES ($1, $2, 1, $3, $4)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			failures = CASE WHEN a.expires_at <= $3 THEN 1 ELSE a.failures + 1 END,
+			locked_until = CASE WHEN a.expires_at <= $3 THEN NULL ELSE a.locked_until END,
+			last_failure_at = $3,
+			-- Блокировка не сокращается новыми неудачами
+			expires_at = GREATEST($4, CASE WHEN a.expires_at <= $3 THEN NULL ELSE a.locked_until END)
+		RETURNING failures, last_failure_at, locked_until`,
+		scope, key, at, at.Add(window),
+	).Scan(&a.Failures, &a.LastFailureAt, &a.LockedUntil)
+	return a, err
+}
+
+func (r *LoginAttemptRepo) LockLogin(scope, key string, until time.Time) error {
+	_, err := r.db.Exec(`
+		INSERT INTO login_attempts (scope, key, failures, last_failure_at, locked_until, expires_at)
+		VALUES ($1, $2, 0, $3, $3, $3)
+		ON CONFLICT (scope, key) DO UPDATE SET locked_until = $3, expires_at = $3`,
+		scope, key, until,
+	)
+	return err
+}
+
+func (r *LoginAttemptRepo) ResetLoginAttempts(scope, key string) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM login_attempts WHERE scope = $1 AND key = $2`, scope, key)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (r *LoginAttemptRepo) DeleteExpiredLoginAttempts(before time.Time) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM login_attempts WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
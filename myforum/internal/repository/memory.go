@@ -0,0 +1,1346 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+// MemoryStore хранит форумы, сообщения и мини-чат в памяти с той же семантикой,
+// что и репозитории Postgres, включая тексты ошибок. Темы и пользователи
+// доступны через Topics и Users: у них пересекаются имена методов с форумами.
+type MemoryStore struct {
+	*memoryDB
+}
+
+type MemoryTopicStore struct {
+	*memoryDB
+}
+
+type MemoryUserStore struct {
+	*memoryDB
+}
+
+// memoryDB — общие таблицы; все хранилища одного MemoryStore видят одни данные
+type memoryDB struct {
+	mu       sync.RWMutex
+	forums   map[int]business.Forum
+	topics   map[int]business.Topic
+	messages map[int]business.Message
+	chat     map[int]business.GlobalMessage
+	users    map[int]business.User
+	// Ревизии по ID сообщения, от старых к новым
+	revisions map[int][]business.MessageRevision
+	// Модераторы форумов: ID форума -> ID пользователя -> время назначения
+	moderators map[int]map[int]time.Time
+	// Refresh-токены, отозванные access-токены (jti -> истечение) и время
+	// выхода пользователя со всех устройств
+	refreshTokens   map[int]business.RefreshToken
+	revokedTokens   map[string]time.Time
+	tokensRevokedAt map[int]time.Time
+	// Одноразовые токены из писем
+	oneTimeTokens map[int]business.OneTimeToken
+	// Неудачные входы: {scope, key} -> запись
+	loginAttempts map[[2]string]loginAttempt
+
+	// Последние выданные ID, как у SERIAL
+	forumSeq, topicSeq, messageSeq, chatSeq, userSeq, revisionSeq, refreshSeq, oneTimeSeq int
+}
+
+var (
+	_ Store        = (*MemoryStore)(nil)
+	_ TopicStore   = (*MemoryTopicStore)(nil)
+	_ AccountStore = (*MemoryUserStore)(nil)
+	_ TokenStore   = (*MemoryUserStore)(nil)
+	_ SearchStore  = (*MemoryStore)(nil)
+
+	_ LoginAttemptStore = (*MemoryStore)(nil)
+)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		memoryDB: &memoryDB{
+			forums:     make(map[int]business.Forum),
+			topics:     make(map[int]business.Topic),
+			messages:   make(map[int]business.Message),
+			chat:       make(map[int]business.GlobalMessage),
+			users:      make(map[int]business.User),
+			revisions:  make(map[int][]business.MessageRevision),
+			moderators: make(map[int]map[int]time.Time),
+
+			refreshTokens:   make(map[int]business.RefreshToken),
+			revokedTokens:   make(map[string]time.Time),
+			tokensRevokedAt: make(map[int]time.Time),
+			oneTimeTokens:   make(map[int]business.OneTimeToken),
+			loginAttempts:   make(map[[2]string]loginAttempt),
+		},
+	}
+}
+
+func (s *MemoryStore) Topics() *MemoryTopicStore {
+	return &MemoryTopicStore{memoryDB: s.memoryDB}
+}
+
+func (s *MemoryStore) Users() *MemoryUserStore {
+	return &MemoryUserStore{memoryDB: s.memoryDB}
+}
+
+//Форумы
+
+func (s *MemoryStore) Create(f business.Forum) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.forumSeq++
+	f.ID = s.forumSeq
+	// created_at всегда берется по умолчанию, как в ForumsRepo.Create
+	f.CreatedAt = time.Now()
+	f.OwnerID = s.existingUserID(f.OwnerID)
+	if f.Visibility == "" {
+		f.Visibility = business.VisibilityPublic
+	}
+	s.forums[f.ID] = f
+	return f.ID, nil
+}
+
+func (s *MemoryStore) GetAll() ([]business.Forum, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var forums []business.Forum
+	for _, f := range s.forums {
+		if f.DeletedAt == nil {
+			forums = append(forums, f)
+		}
+	}
+	sort.Slice(forums, func(i, j int) bool { return forums[i].ID < forums[j].ID })
+	return forums, nil
+}
+
+func (s *MemoryStore) GetAllPage(p PageRequest) ([]business.Forum, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var forums []business.Forum
+	for _, f := range s.forums {
+		if f.DeletedAt == nil {
+			forums = append(forums, f)
+		}
+	}
+	forums, hasMore := keysetPage(forums, p, func(f business.Forum) (time.Time, int) { return f.CreatedAt, f.ID })
+	return forums, hasMore, nil
+}
+
+func (s *MemoryStore) GetByID(id int) (*business.Forum, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, ok := s.liveForum(id)
+	if !ok {
+		return nil, errors.New("forum not found")
+	}
+	return &f, nil
+}
+
+func (s *MemoryStore) Update(id int, f business.Forum) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.liveForum(id)
+	if !ok {
+		return errors.New("no forum found with the given ID")
+	}
+	stored.Title = f.Title
+	stored.Description = f.Description
+	if f.Visibility != "" {
+		stored.Visibility = f.Visibility
+	}
+	s.forums[id] = stored
+	return nil
+}
+
+// Delete переносит форум в корзину; его сообщения скрываются вместе с ним
+func (s *MemoryStore) Delete(id int, deletedBy int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.liveForum(id)
+	if !ok {
+		return errors.New("no forum found with the given ID")
+	}
+	now := time.Now()
+	f.DeletedAt = &now
+	f.DeletedBy = s.existingUserID(deletedBy)
+	s.forums[id] = f
+	return nil
+}
+
+//Модераторы форумов
+
+func (s *MemoryStore) GetForumModerators(forumID int) ([]business.ForumModerator, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	moderators := []business.ForumModerator{}
+	for userID, addedAt := range s.moderators[forumID] {
+		moderators = append(moderators, business.ForumModerator{
+			ForumID:  forumID,
+			UserID:   userID,
+			Username: s.users[userID].Username,
+			AddedAt:  addedAt,
+		})
+	}
+	sortByKey(moderators, func(m business.ForumModerator) (time.Time, int) { return m.AddedAt, m.UserID })
+	return moderators, nil
+}
+
+func (s *MemoryStore) IsForumModerator(forumID, userID int) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.moderators[forumID][userID]
+	return ok, nil
+}
+
+func (s *MemoryStore) AddForumModerator(forumID, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Внешние ключи forum_moderators
+	if _, ok := s.forums[forumID]; !ok {
+		return errors.New("no forum found with the given ID")
+	}
+	if _, ok := s.users[userID]; !ok {
+		return ErrUserNotFound
+	}
+	if s.moderators[forumID] == nil {
+		s.moderators[forumID] = make(map[int]time.Time)
+	}
+	if _, ok := s.moderators[forumID][userID]; !ok {
+		s.moderators[forumID][userID] = time.Now()
+	}
+	return nil
+}
+
+func (s *MemoryStore) RemoveForumModerator(forumID, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.moderators[forumID][userID]; !ok {
+		return ErrModeratorNotFound
+	}
+	delete(s.moderators[forumID], userID)
+	return nil
+}
+
+//Сообщения
+
+func (s *MemoryStore) CreateMessage(msg business.Message) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.liveForum(msg.ForumID); !ok {
+		return 0, fmt.Errorf("forum with ID %d not found", msg.ForumID)
+	}
+
+	if msg.TopicID == 0 {
+		msg.TopicID = s.defaultTopicID(msg.ForumID)
+	} else if _, ok := s.topics[msg.TopicID]; !ok {
+		return 0, fmt.Errorf("insert message failed: topic %d not found", msg.TopicID)
+	}
+	if msg.ParentID != 0 {
+		if _, ok := s.messages[msg.ParentID]; !ok {
+			return 0, fmt.Errorf("insert message failed: parent message %d not found", msg.ParentID)
+		}
+	}
+	// Внешний ключ author_id
+	if _, ok := s.users[msg.AuthorID]; msg.AuthorID != 0 && !ok {
+		return 0, fmt.Errorf("insert message failed: author %d not found", msg.AuthorID)
+	}
+
+	s.messageSeq++
+	msg.ID = s.messageSeq
+	s.messages[msg.ID] = cloneMessage(msg)
+	return msg.ID, nil
+}
+
+func (s *MemoryStore) GetMessages(forumID int) ([]business.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.messagesWhere(func(m business.Message) bool { return m.ForumID == forumID }), nil
+}
+
+func (s *MemoryStore) GetMessagesPage(forumID int, p PageRequest) ([]business.Message, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	messages := s.messagesWhere(func(m business.Message) bool { return m.ForumID == forumID })
+	messages, hasMore := keysetPage(messages, p, func(m business.Message) (time.Time, int) { return m.CreatedAt, m.ID })
+	return messages, hasMore, nil
+}
+
+func (s *MemoryStore) GetMessageByID(messageID int) (*business.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m, ok := s.messages[messageID]
+	if !ok || !s.isLive(m) {
+		return nil, sql.ErrNoRows
+	}
+	m = s.withAuthor(m)
+	return &m, nil
+}
+
+func (s *MemoryStore) PutMessage(messageID int, updatedContent string, editorID int) (*business.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.messages[messageID]
+	if !ok || !s.isLive(m) {
+		return nil, fmt.Errorf("failed to update message: %w", sql.ErrNoRows)
+	}
+
+	now := time.Now()
+	s.revisionSeq++
+	s.revisions[messageID] = append(s.revisions[messageID], business.MessageRevision{
+		ID:        s.revisionSeq,
+		MessageID: messageID,
+		Content:   m.Content,
+		EditorID:  editorID,
+		EditedAt:  now,
+	})
+
+	m.Content = updatedContent
+	m.EditedAt = &now
+	s.messages[messageID] = m
+
+	m = cloneMessage(m)
+	return &m, nil
+}
+
+func (s *MemoryStore) GetMessageRevisions(messageID int) ([]business.MessageRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions := make([]business.MessageRevision, 0, len(s.revisions[messageID]))
+	for _, rev := range s.revisions[messageID] {
+		// Имя редактора берется на момент чтения, как JOIN в базе
+		if u, ok := s.users[rev.EditorID]; ok {
+			rev.Editor = u.Username
+		} else {
+			rev.EditorID = 0
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, nil
+}
+
+// DeleteMessage, как и в базе, не считает ошибкой отсутствие сообщения
+func (s *MemoryStore) DeleteMessage(id int, deletedBy int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if m, ok := s.messages[id]; ok && m.DeletedAt == nil {
+		now := time.Now()
+		m.DeletedAt = &now
+		m.DeletedBy = s.existingUserID(deletedBy)
+		s.messages[id] = m
+	}
+	return nil
+}
+
+//Корзина
+
+func (s *MemoryStore) GetDeletedForums() ([]business.Forum, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	forums := []business.Forum{}
+	for _, f := range s.forums {
+		if f.DeletedAt != nil {
+			forums = append(forums, f)
+		}
+	}
+	// Недавно удаленные первыми
+	sort.Slice(forums, func(i, j int) bool {
+		return keyLess(*forums[j].DeletedAt, forums[j].ID, *forums[i].DeletedAt, forums[i].ID)
+	})
+	return forums, nil
+}
+
+func (s *MemoryStore) GetDeletedMessages() ([]business.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	messages := []business.Message{}
+	for _, m := range s.messages {
+		if m.DeletedAt != nil {
+			messages = append(messages, s.withAuthor(m))
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return keyLess(*messages[j].DeletedAt, messages[j].ID, *messages[i].DeletedAt, messages[i].ID)
+	})
+	return messages, nil
+}
+
+func (s *MemoryStore) RestoreForum(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.forums[id]
+	if !ok || f.DeletedAt == nil {
+		return ErrForumNotInTrash
+	}
+	f.DeletedAt, f.DeletedBy = nil, 0
+	s.forums[id] = f
+	return nil
+}
+
+func (s *MemoryStore) RestoreMessage(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.messages[id]
+	if !ok || m.DeletedAt == nil {
+		return ErrMessageNotInTrash
+	}
+	m.DeletedAt, m.DeletedBy = nil, 0
+	s.messages[id] = m
+	return nil
+}
+
+// Purge удаляет просроченное окончательно: форумы вместе с темами и всеми сообщениями
+func (s *MemoryStore) Purge(retention time.Duration) (PurgeStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-retention)
+	expired := func(at *time.Time) bool { return at != nil && at.Before(cutoff) }
+
+	purgedForums := make(map[int]bool)
+	for id, f := range s.forums {
+		if expired(f.DeletedAt) {
+			purgedForums[id] = true
+		}
+	}
+
+	var stats PurgeStats
+	stats.Messages = int64(s.deleteMessagesWhere(func(m business.Message) bool {
+		return expired(m.DeletedAt) || purgedForums[m.ForumID]
+	}))
+	for id := range purgedForums {
+		delete(s.forums, id)
+		delete(s.moderators, id)
+		stats.Forums++
+	}
+	for topicID, t := range s.topics {
+		if purgedForums[t.ForumID] {
+			delete(s.topics, topicID)
+		}
+	}
+	return stats, nil
+}
+
+//Мини-чат
+
+func (s *MemoryStore) CreateGlobalMessage(msg business.GlobalMessage) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Внешний ключ author_id
+	if _, ok := s.users[msg.AuthorID]; msg.AuthorID != 0 && !ok {
+		return 0, fmt.Errorf("insert global message failed: author %d not found", msg.AuthorID)
+	}
+
+	s.chatSeq++
+	msg.ID = s.chatSeq
+	s.chat[msg.ID] = msg
+	return msg.ID, nil
+}
+
+// GetGlobalMessages возвращает последние сообщения, новые первыми
+func (s *MemoryStore) GetGlobalMessages(limit int) ([]business.GlobalMessage, error) {
+	history := s.sortedChat()
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	return limitSlice(history, limit), nil
+}
+
+// GetGlobalChatHistory возвращает первые limit сообщений в хронологическом порядке
+func (s *MemoryStore) GetGlobalChatHistory(limit int) ([]business.GlobalMessage, error) {
+	return limitSlice(s.sortedChat(), limit), nil
+}
+
+func (s *MemoryStore) GetGlobalChatPage(p PageRequest) ([]business.GlobalMessage, bool, error) {
+	history, hasMore := keysetPage(s.sortedChat(), p, func(m business.GlobalMessage) (time.Time, int) { return m.CreatedAt, m.ID })
+	return history, hasMore, nil
+}
+
+func (s *MemoryStore) DeleteGlobalMessage(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.chat, id)
+	return nil
+}
+
+func (s *MemoryStore) sortedChat() []business.GlobalMessage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var history []business.GlobalMessage
+	for _, m := range s.chat {
+		if u, ok := s.users[m.AuthorID]; ok {
+			m.Author = u.Username
+		}
+		history = append(history, m)
+	}
+	sortByKey(history, func(m business.GlobalMessage) (time.Time, int) { return m.CreatedAt, m.ID })
+	return history
+}
+
+//Темы
+
+func (s *MemoryTopicStore) Create(t business.Topic) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.liveForum(t.ForumID); !ok {
+		return 0, fmt.Errorf("forum with ID %d not found", t.ForumID)
+	}
+
+	s.topicSeq++
+	t.ID = s.topicSeq
+	t.IsDefault = false
+	t.CreatedAt = time.Now()
+	s.topics[t.ID] = t
+	return t.ID, nil
+}
+
+// GetByForum возвращает темы форума, тема по умолчанию идет первой
+func (s *MemoryTopicStore) GetByForum(forumID int) ([]business.Topic, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var topics []business.Topic
+	for _, t := range s.topics {
+		if t.ForumID == forumID {
+			topics = append(topics, t)
+		}
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		if topics[i].IsDefault != topics[j].IsDefault {
+			return topics[i].IsDefault
+		}
+		return keyLess(topics[i].CreatedAt, topics[i].ID, topics[j].CreatedAt, topics[j].ID)
+	})
+	return topics, nil
+}
+
+func (s *MemoryTopicStore) GetByID(id int) (*business.Topic, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.topics[id]
+	if !ok {
+		return nil, errors.New("topic not found")
+	}
+	return &t, nil
+}
+
+func (s *MemoryTopicStore) Update(id int, t business.Topic) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.topics[id]
+	if !ok {
+		return errors.New("no topic found with the given ID")
+	}
+	stored.Title = t.Title
+	stored.Desc = t.Desc
+	s.topics[id] = stored
+	return nil
+}
+
+// Delete удаляет тему, а ее сообщения переносит в корзину и в тему по умолчанию
+func (s *MemoryTopicStore) Delete(id int, deletedBy int) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.topics[id]
+	if !ok || t.IsDefault {
+		return nil, errors.New("no topic found with the given ID")
+	}
+	defaultID := s.defaultTopicID(t.ForumID)
+	now := time.Now()
+	var deleted []int
+	for msgID, m := range s.messages {
+		if m.TopicID != id {
+			continue
+		}
+		m.TopicID = defaultID
+		if m.DeletedAt == nil {
+			m.DeletedAt = &now
+			m.DeletedBy = s.existingUserID(deletedBy)
+			deleted = append(deleted, msgID)
+		}
+		s.messages[msgID] = m
+	}
+	delete(s.topics, id)
+	sort.Ints(deleted)
+	return deleted, nil
+}
+
+func (s *MemoryTopicStore) GetMessages(topicID int) ([]business.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.messagesWhere(func(m business.Message) bool { return m.TopicID == topicID }), nil
+}
+
+func (s *MemoryTopicStore) DefaultTopicID(forumID int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.forums[forumID]; !ok {
+		return 0, fmt.Errorf("forum with ID %d not found", forumID)
+	}
+	return s.defaultTopicID(forumID), nil
+}
+
+//Профили
+
+func (s *MemoryStore) GetUserIDByUsername(username string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.users {
+		if u.Username == username {
+			return u.ID, nil
+		}
+	}
+	return 0, ErrUserNotFound
+}
+
+func (s *MemoryStore) GetUserActivity(authorID int, withMembers bool) (business.UserActivity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	activity := business.UserActivity{Forums: []business.ForumActivity{}}
+	byForum := make(map[int]int) // ID форума -> индекс в activity.Forums
+	for _, m := range s.authorMessages(authorID, withMembers) {
+		i, ok := byForum[m.ForumID]
+		if !ok {
+			i = len(activity.Forums)
+			byForum[m.ForumID] = i
+			activity.Forums = append(activity.Forums, business.ForumActivity{
+				ForumID: m.ForumID,
+				Title:   s.forums[m.ForumID].Title,
+			})
+		}
+		activity.Forums[i].MessageCount++
+		activity.Forums[i].LastPostAt = m.CreatedAt // сообщения идут по времени
+		activity.MessageCount++
+	}
+	sort.Slice(activity.Forums, func(i, j int) bool {
+		return activity.Forums[i].LastPostAt.After(activity.Forums[j].LastPostAt)
+	})
+	return activity, nil
+}
+
+func (s *MemoryStore) GetUserMessagesPage(authorID int, withMembers bool, p PageRequest) ([]business.Message, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	messages := s.authorMessages(authorID, withMembers)
+	messages, hasMore := keysetPage(messages, p, func(m business.Message) (time.Time, int) { return m.CreatedAt, m.ID })
+	return messages, hasMore, nil
+}
+
+// authorMessages — живые сообщения автора по времени, как authorMessage в ForumsRepo
+func (db *memoryDB) authorMessages(authorID int, withMembers bool) []business.Message {
+	return db.messagesWhere(func(m business.Message) bool {
+		return m.AuthorID == authorID &&
+			(withMembers || db.forums[m.ForumID].Visibility != business.VisibilityMembers)
+	})
+}
+
+//Поиск
+
+// Search вместо полнотекстового поиска ищет подстроку без учета регистра, ранг —
+// число совпадений. Фильтры, видимость, порядок и страницы — как у SearchRepo.
+func (s *MemoryStore) Search(p SearchParams) ([]business.SearchResult, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	match := regexp.MustCompile("(?i)" + regexp.QuoteMeta(p.Query))
+	filter := func(forumID int, createdAt time.Time) bool {
+		return (p.ForumID == 0 || forumID == p.ForumID) &&
+			(p.From.IsZero() || !createdAt.Before(p.From)) &&
+			(p.To.IsZero() || createdAt.Before(p.To))
+	}
+	result := func(typ string, id, forumID int, author, body string, createdAt time.Time) (business.SearchResult, bool) {
+		rank := len(match.FindAllStringIndex(body, -1))
+		return business.SearchResult{
+			Type:       typ,
+			ID:         id,
+			ForumID:    forumID,
+			ForumTitle: s.forums[forumID].Title,
+			Author:     author,
+			Snippet:    highlight(match.ReplaceAllStringFunc(body, func(m string) string { return highlightStart + m + highlightStop })),
+			Rank:       float64(rank),
+			CreatedAt:  createdAt,
+		}, rank > 0
+	}
+
+	var results []business.SearchResult
+	if (p.Type == "" || p.Type == business.SearchResultForum) && p.Author == "" {
+		for _, f := range s.forums {
+			if f.DeletedAt != nil || !filter(f.ID, f.CreatedAt) {
+				continue
+			}
+			if res, ok := result(business.SearchResultForum, f.ID, f.ID, "", f.Title+" — "+f.Description, f.CreatedAt); ok {
+				results = append(results, res)
+			}
+		}
+	}
+	if p.Type == "" || p.Type == business.SearchResultMessage {
+		messages := s.messagesWhere(func(m business.Message) bool {
+			return filter(m.ForumID, m.CreatedAt) &&
+				(p.WithMembers || s.forums[m.ForumID].Visibility != business.VisibilityMembers)
+		})
+		for _, m := range messages {
+			if p.Author != "" && m.Author != p.Author {
+				continue
+			}
+			if res, ok := result(business.SearchResultMessage, m.ID, m.ForumID, m.Author, m.Content, m.CreatedAt); ok {
+				results = append(results, res)
+			}
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Rank != b.Rank {
+			return a.Rank > b.Rank
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	})
+
+	limit := p.Limit
+	if limit <= 0 || limit > MaxPageSize {
+		limit = DefaultPageSize
+	}
+	if p.Offset >= len(results) {
+		return nil, false, nil
+	}
+	results = results[p.Offset:]
+	hasMore := len(results) > limit
+	if hasMore {
+		results = results[:limit]
+	}
+	return results, hasMore, nil
+}
+
+//Пользователи
+
+func (s *MemoryUserStore) Create(user business.User) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Уникальность username и email обеспечивается ограничениями таблицы users
+	for _, u := range s.users {
+		if u.Username == user.Username {
+			return 0, fmt.Errorf("duplicate username %q", user.Username)
+		}
+		if u.Email == user.Email {
+			return 0, fmt.Errorf("duplicate email %q", user.Email)
+		}
+	}
+
+	s.userSeq++
+	user.ID = s.userSeq
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	s.users[user.ID] = user
+	return user.ID, nil
+}
+
+func (s *MemoryUserStore) GetByUsername(username string) (*business.User, error) {
+	return s.findUser(func(u business.User) bool { return u.Username == username })
+}
+
+func (s *MemoryUserStore) GetByEmail(email string) (*business.User, error) {
+	return s.findUser(func(u business.User) bool { return u.Email == email })
+}
+
+func (s *MemoryUserStore) UpdatePassword(userID int, hashedPassword string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, ok := s.users[userID]; ok {
+		u.Password = hashedPassword
+		u.UpdatedAt = time.Now()
+		s.users[userID] = u
+	}
+	return nil
+}
+
+func (s *MemoryUserStore) MarkEmailVerified(userID int, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	u.EmailVerifiedAt = &at
+	u.UpdatedAt = time.Now()
+	s.users[userID] = u
+	return nil
+}
+
+func (s *MemoryUserStore) UpdateProfile(userID int, p business.ProfileUpdate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	if p.Bio != nil {
+		u.Bio = *p.Bio
+	}
+	if p.AvatarURL != nil {
+		u.AvatarURL = *p.AvatarURL
+	}
+	u.UpdatedAt = time.Now()
+	s.users[userID] = u
+	return nil
+}
+
+func (s *MemoryUserStore) UpdateEmail(userID int, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	for _, other := range s.users {
+		if other.ID != userID && other.Email == email {
+			return ErrEmailTaken
+		}
+	}
+	u.Email = email
+	u.EmailVerifiedAt = nil
+	u.UpdatedAt = time.Now()
+	s.users[userID] = u
+	return nil
+}
+
+// DeleteUser повторяет UserRepo.DeleteUser вместе с действиями внешних ключей
+func (s *MemoryUserStore) DeleteUser(userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	delete(s.users, userID)
+
+	for id, m := range s.messages {
+		if m.AuthorID == userID {
+			m.AuthorID = 0
+			m.Author = business.DeletedAuthor
+		}
+		if m.Quote != nil && m.Quote.Author == u.Username {
+			quote := *m.Quote
+			quote.Author = business.DeletedAuthor
+			m.Quote = &quote
+		}
+		if m.DeletedBy == userID {
+			m.DeletedBy = 0
+		}
+		s.messages[id] = m
+	}
+	for id, msg := range s.chat {
+		if msg.AuthorID == userID {
+			msg.AuthorID = 0
+			msg.Author = business.DeletedAuthor
+			s.chat[id] = msg
+		}
+	}
+	for id, f := range s.forums {
+		if f.OwnerID == userID {
+			f.OwnerID = 0
+		}
+		if f.DeletedBy == userID {
+			f.DeletedBy = 0
+		}
+		s.forums[id] = f
+	}
+	for _, mods := range s.moderators {
+		delete(mods, userID)
+	}
+	for id, t := range s.refreshTokens {
+		if t.UserID == userID {
+			delete(s.refreshTokens, id)
+		}
+	}
+	for id, t := range s.oneTimeTokens {
+		if t.UserID == userID {
+			delete(s.oneTimeTokens, id)
+		}
+	}
+	delete(s.tokensRevokedAt, userID)
+	return nil
+}
+
+func (s *MemoryUserStore) findUser(match func(business.User) bool) (*business.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.users {
+		if match(u) {
+			return &u, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// GetUserByID, как и запрос в базе, не возвращает хеш пароля
+func (db *memoryDB) GetUserByID(userID int) (*business.User, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	u, ok := db.users[userID]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	u.Password = ""
+	return &u, nil
+}
+
+func (db *memoryDB) UpdateUserRole(userID int, role string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	u, ok := db.users[userID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	u.Role = role
+	u.UpdatedAt = time.Now()
+	db.users[userID] = u
+	return nil
+}
+
+//Токены
+
+func (db *memoryDB) CreateRefreshToken(t business.RefreshToken) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.users[t.UserID]; !ok {
+		return 0, fmt.Errorf("refresh token: user %d does not exist", t.UserID)
+	}
+	for _, existing := range db.refreshTokens {
+		if existing.TokenHash == t.TokenHash {
+			return 0, errors.New("refresh token already exists")
+		}
+	}
+	db.refreshSeq++
+	t.ID = db.refreshSeq
+	t.RevokedAt = nil
+	db.refreshTokens[t.ID] = t
+	return t.ID, nil
+}
+
+func (db *memoryDB) GetRefreshToken(tokenHash string) (*business.RefreshToken, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, t := range db.refreshTokens {
+		if t.TokenHash == tokenHash {
+			return &t, nil
+		}
+	}
+	return nil, ErrRefreshTokenNotFound
+}
+
+func (db *memoryDB) RevokeRefreshToken(id, replacedBy int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	t, ok := db.refreshTokens[id]
+	if !ok || t.RevokedAt != nil {
+		return ErrRefreshTokenNotFound
+	}
+	now := time.Now()
+	t.RevokedAt = &now
+	t.ReplacedBy = replacedBy
+	db.refreshTokens[id] = t
+	return nil
+}
+
+func (db *memoryDB) RevokeUserSessions(userID int, at time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.users[userID]; !ok {
+		return ErrUserNotFound
+	}
+	for id, t := range db.refreshTokens {
+		if t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &at
+			db.refreshTokens[id] = t
+		}
+	}
+	db.tokensRevokedAt[userID] = at
+	return nil
+}
+
+func (db *memoryDB) RevokeAccessToken(jti string, userID int, expiresAt time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.revokedTokens[jti]; !ok {
+		db.revokedTokens[jti] = expiresAt
+	}
+	return nil
+}
+
+func (db *memoryDB) IsAccessTokenRevoked(jti string, userID int, issuedAt time.Time) (bool, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if _, ok := db.revokedTokens[jti]; ok {
+		return true, nil
+	}
+	at, ok := db.tokensRevokedAt[userID]
+	return ok && at.After(issuedAt), nil
+}
+
+func (db *memoryDB) DeleteExpiredTokens(before time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var total int64
+	for id, t := range db.refreshTokens {
+		if t.ExpiresAt.Before(before) {
+			delete(db.refreshTokens, id)
+			total++
+		}
+	}
+	for jti, expiresAt := range db.revokedTokens {
+		if expiresAt.Before(before) {
+			delete(db.revokedTokens, jti)
+			total++
+		}
+	}
+	for id, t := range db.oneTimeTokens {
+		if t.ExpiresAt.Before(before) {
+			delete(db.oneTimeTokens, id)
+			total++
+		}
+	}
+	return total, nil
+}
+
+func (db *memoryDB) CreateOneTimeToken(t business.OneTimeToken) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.users[t.UserID]; !ok {
+		return 0, fmt.Errorf("one-time token: user %d does not exist", t.UserID)
+	}
+	for id, existing := range db.oneTimeTokens {
+		if existing.TokenHash == t.TokenHash {
+			return 0, errors.New("one-time token already exists")
+		}
+		// Действует только последняя ссылка, как в TokenRepo
+		if existing.UserID == t.UserID && existing.Purpose == t.Purpose && existing.UsedAt == nil {
+			usedAt := t.CreatedAt
+			existing.UsedAt = &usedAt
+			db.oneTimeTokens[id] = existing
+		}
+	}
+	db.oneTimeSeq++
+	t.ID = db.oneTimeSeq
+	t.UsedAt = nil
+	db.oneTimeTokens[t.ID] = t
+	return t.ID, nil
+}
+
+func (db *memoryDB) UseOneTimeToken(purpose, tokenHash string, at time.Time) (*business.OneTimeToken, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for id, t := range db.oneTimeTokens {
+		if t.TokenHash != tokenHash || t.Purpose != purpose {
+			continue
+		}
+		if t.UsedAt != nil || !t.ExpiresAt.After(at) {
+			break
+		}
+		t.UsedAt = &at
+		db.oneTimeTokens[id] = t
+		return &t, nil
+	}
+	return nil, ErrOneTimeTokenNotFound
+}
+
+//Попытки входа
+
+// loginAttempt — строка login_attempts
+type loginAttempt struct {
+	business.LoginAttempts
+	expiresAt time.Time
+}
+
+func (db *memoryDB) GetLoginAttempts(scope, key string, at time.Time) (business.LoginAttempts, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	a, ok := db.loginAttempts[[2]string{scope, key}]
+	if !ok || !a.expiresAt.After(at) {
+		return business.LoginAttempts{Scope: scope, Key: key}, nil
+	}
+	return cloneLoginAttempts(a.LoginAttempts), nil
+}
+
+func (db *memoryDB) RecordLoginFailure(scope, key string, at time.Time, window time.Duration) (business.LoginAttempts, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	id := [2]string{scope, key}
+	a, ok := db.loginAttempts[id]
+	if !ok || !a.expiresAt.After(at) {
+		a = loginAttempt{LoginAttempts: business.LoginAttempts{Scope: scope, Key: key}}
+	}
+	a.Failures++
+	a.LastFailureAt = at
+	// Блокировка не сокращается новыми неудачами
+	a.expiresAt = at.Add(window)
+	if a.LockedUntil != nil && a.LockedUntil.After(a.expiresAt) {
+		a.expiresAt = *a.LockedUntil
+	}
+	db.loginAttempts[id] = a
+	return cloneLoginAttempts(a.LoginAttempts), nil
+}
+
+func (db *memoryDB) LockLogin(scope, key string, until time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	id := [2]string{scope, key}
+	a, ok := db.loginAttempts[id]
+	if !ok {
+		a = loginAttempt{LoginAttempts: business.LoginAttempts{Scope: scope, Key: key}}
+	}
+	a.LockedUntil = &until
+	a.expiresAt = until
+	db.loginAttempts[id] = a
+	return nil
+}
+
+func (db *memoryDB) ResetLoginAttempts(scope, key string) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	id := [2]string{scope, key}
+	_, ok := db.loginAttempts[id]
+	delete(db.loginAttempts, id)
+	return ok, nil
+}
+
+func (db *memoryDB) DeleteExpiredLoginAttempts(before time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var total int64
+	for id, a := range db.loginAttempts {
+		if a.expiresAt.Before(before) {
+			delete(db.loginAttempts, id)
+			total++
+		}
+	}
+	return total, nil
+}
+
+func cloneLoginAttempts(a business.LoginAttempts) business.LoginAttempts {
+	if a.LockedUntil != nil {
+		until := *a.LockedUntil
+		a.LockedUntil = &until
+	}
+	return a
+}
+
+// defaultTopicID возвращает тему форума по умолчанию, создавая ее.
+// Вызывается под блокировкой записи.
+func (db *memoryDB) defaultTopicID(forumID int) int {
+	for _, t := range db.topics {
+		if t.ForumID == forumID && t.IsDefault {
+			return t.ID
+		}
+	}
+
+	db.topicSeq++
+	db.topics[db.topicSeq] = business.Topic{
+		ID:        db.topicSeq,
+		ForumID:   forumID,
+		Title:     "Общее обсуждение",
+		Desc:      "Сообщения без отдельной темы",
+		IsDefault: true,
+		CreatedAt: time.Now(),
+	}
+	return db.topicSeq
+}
+
+// messagesWhere возвращает подходящие сообщения в хронологическом порядке
+// messagesWhere возвращает подходящие сообщения вне корзины
+func (db *memoryDB) messagesWhere(match func(business.Message) bool) []business.Message {
+	var messages []business.Message
+	for _, m := range db.messages {
+		if db.isLive(m) && match(m) {
+			messages = append(messages, db.withAuthor(m))
+		}
+	}
+	sortByKey(messages, func(m business.Message) (time.Time, int) { return m.CreatedAt, m.ID })
+	return messages
+}
+
+// liveForum возвращает форум, если он есть и не в корзине
+func (db *memoryDB) liveForum(id int) (business.Forum, bool) {
+	f, ok := db.forums[id]
+	return f, ok && f.DeletedAt == nil
+}
+
+// isLive сообщает, что сообщение и его форум не в корзине
+func (db *memoryDB) isLive(m business.Message) bool {
+	if m.DeletedAt != nil {
+		return false
+	}
+	_, ok := db.liveForum(m.ForumID)
+	return ok
+}
+
+// existingUserID повторяет внешний ключ deleted_by: несуществующий пользователь становится 0
+func (db *memoryDB) existingUserID(id int) int {
+	if _, ok := db.users[id]; !ok {
+		return 0
+	}
+	return id
+}
+
+// deleteMessagesWhere окончательно удаляет сообщения, а у ответов на них обнуляет
+// parent_id (ON DELETE SET NULL). Возвращает число удаленных. Вызывается под
+// блокировкой записи.
+func (db *memoryDB) deleteMessagesWhere(match func(business.Message) bool) int {
+	deleted := make(map[int]bool)
+	for id, m := range db.messages {
+		if match(m) {
+			deleted[id] = true
+			delete(db.messages, id)
+			delete(db.revisions, id)
+		}
+	}
+	for id, m := range db.messages {
+		if deleted[m.ParentID] {
+			m.ParentID = 0
+			db.messages[id] = m
+		}
+	}
+	return len(deleted)
+}
+
+// withAuthor копирует сообщение и подставляет текущее имя автора, как
+// messagesFrom в ForumsRepo
+func (db *memoryDB) withAuthor(m business.Message) business.Message {
+	m = cloneMessage(m)
+	if u, ok := db.users[m.AuthorID]; ok {
+		m.Author = u.Username
+	}
+	return m
+}
+
+// cloneMessage копирует сообщение вместе с цитатой, чтобы вызывающий
+// не мог изменить сохраненные данные
+func cloneMessage(m business.Message) business.Message {
+	if m.Quote != nil {
+		quote := *m.Quote
+		m.Quote = &quote
+	}
+	if m.EditedAt != nil {
+		editedAt := *m.EditedAt
+		m.EditedAt = &editedAt
+	}
+	if m.DeletedAt != nil {
+		deletedAt := *m.DeletedAt
+		m.DeletedAt = &deletedAt
+	}
+	return m
+}
+
+func keyLess(at time.Time, aID int, bt time.Time, bID int) bool {
+	if !at.Equal(bt) {
+		return at.Before(bt)
+	}
+	return aID < bID
+}
+
+func sortByKey[T any](items []T, key func(T) (time.Time, int)) {
+	sort.Slice(items, func(i, j int) bool {
+		at, aID := key(items[i])
+		bt, bID := key(items[j])
+		return keyLess(at, aID, bt, bID)
+	})
+}
+
+// keysetPage повторяет для записей в памяти то, что PageRequest.keyset и
+// trimPage делают с запросом: условие по курсору, сортировку и лимит.
+func keysetPage[T any](items []T, p PageRequest, key func(T) (time.Time, int)) ([]T, bool) {
+	sortByKey(items, key)
+	if p.Backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	var page []T
+	for _, item := range items {
+		if p.Cursor != nil {
+			t, id := key(item)
+			after := keyLess(p.Cursor.CreatedAt, p.Cursor.ID, t, id)
+			before := keyLess(t, id, p.Cursor.CreatedAt, p.Cursor.ID)
+			if (!p.Backward && !after) || (p.Backward && !before) {
+				continue
+			}
+		}
+		page = append(page, item)
+		if len(page) > p.limit() {
+			break
+		}
+	}
+	return trimPage(page, p)
+}
+
+func limitSlice[T any](items []T, limit int) []T {
+	if limit >= 0 && len(items) > limit {
+		return items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+var ErrModeratorNotFound = errors.New("user is not a moderator of this forum")
+
+// GetForumModerators возвращает модераторов форума в порядке назначения
+func (r *ForumsRepo) GetForumModerators(forumID int) ([]business.ForumModerator, error) {
+	rows, err := r.DB.Query(`
+		SELECT fm.forum_id, fm.user_id, u.username, fm.added_at
+		FROM forum_moderators fm
+		JOIN users u ON u.id = fm.user_id
+		WHERE fm.forum_id = $1
+		ORDER BY fm.added_at, fm.user_id`, forumID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	moderators := []business.ForumModerator{}
+	for rows.Next() {
+		var m business.ForumModerator
+		if err := rows.Scan(&m.ForumID, &m.UserID, &m.Username, &m.AddedAt); err != nil {
+			return nil, err
+		}
+		moderators = append(moderators, m)
+	}
+	return moderators, rows.Err()
+}
+
+func (r *ForumsRepo) IsForumModerator(forumID, userID int) (bool, error) {
+	var exists bool
+	err := r.DB.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM forum_moderators WHERE forum_id = $1 AND user_id = $2)`,
+		forumID, userID,
+	).Scan(&exists)
+	return exists, err
+}
+
+// AddForumModerator назначает модератора; повторное назначение ничего не меняет
+func (r *ForumsRepo) AddForumModerator(forumID, userID int) error {
+	_, err := r.DB.Exec(
+		`INSERT INTO forum_moderators (forum_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		forumID, userID,
+	)
+	return err
+}
+
+func (r *ForumsRepo) RemoveForumModerator(forumID, userID int) error {
+	result, err := r.DB.Exec(
+		`DELETE FROM forum_moderators WHERE forum_id = $1 AND user_id = $2`,
+		forumID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrModeratorNotFound
+	}
+	return nil
+}
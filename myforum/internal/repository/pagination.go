@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor указывает на запись по ключу (created_at, id)
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int       `json:"id"`
+}
+
+// Encode возвращает непрозрачный токен курсора для URL
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// PageRequest — запрос страницы по ключу (created_at, id).
+// Без курсора страница берется с начала, а при Backward — с конца.
+type PageRequest struct {
+	Cursor   *Cursor
+	Backward bool // к более ранним записям, то есть до курсора
+	Limit    int
+}
+
+func (p PageRequest) limit() int {
+	if p.Limit <= 0 {
+		return DefaultPageSize
+	}
+	if p.Limit > MaxPageSize {
+		return MaxPageSize
+	}
+	return p.Limit
+}
+
+// keyset дополняет запрос условием по курсору, сортировкой и LIMIT.
+// where — уже имеющиеся условия (может быть пустым), args — их параметры.
+// Выбирается на одну запись больше лимита, чтобы понять, есть ли продолжение.
+func (p PageRequest) keyset(where string, args []interface{}) (string, []interface{}) {
+	op, dir := ">", "ASC"
+	if p.Backward {
+		op, dir = "<", "DESC"
+	}
+
+	if p.Cursor != nil {
+		cond := fmt.Sprintf("(created_at, id) %s ($%d, $%d)", op, len(args)+1, len(args)+2)
+		args = append(args, p.Cursor.CreatedAt, p.Cursor.ID)
+		if where == "" {
+			where = cond
+		} else {
+			where = where + " AND " + cond
+		}
+	}
+
+	var query string
+	if where != "" {
+		query = " WHERE " + where
+	}
+	query += fmt.Sprintf(" ORDER BY created_at %s, id %s LIMIT %d", dir, dir, p.limit()+1)
+	return query, args
+}
+
+// trimPage отрезает лишнюю запись и возвращает страницу в хронологическом порядке
+func trimPage[T any](items []T, p PageRequest) ([]T, bool) {
+	hasMore := len(items) > p.limit()
+	if hasMore {
+		items = items[:p.limit()]
+	}
+	if p.Backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	return items, hasMore
+}
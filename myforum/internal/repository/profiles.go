@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+// authorMessage — живые сообщения автора с ID $1; без withMembers — вне форумов
+// только для участников
+func authorMessage(withMembers bool) string {
+	where := "author_id = $1 AND " + liveMessage
+	if !withMembers {
+		where += ` AND forum_id NOT IN (SELECT id FROM forums WHERE visibility = 'members')`
+	}
+	return where
+}
+
+func (r *ForumsRepo) GetUserIDByUsername(username string) (int, error) {
+	var id int
+	err := r.DB.QueryRow(`SELECT id FROM users WHERE username = $1`, username).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, ErrUserNotFound
+	}
+	return id, err
+}
+
+// GetUserActivity считает сообщения автора по форумам, начиная с форума
+// с самым свежим сообщением
+func (r *ForumsRepo) GetUserActivity(authorID int, withMembers bool) (business.UserActivity, error) {
+	activity := business.UserActivity{Forums: []business.ForumActivity{}}
+	rows, err := r.DB.Query(`
+		SELECT m.forum_id, f.name, COUNT(*), MAX(m.created_at)
+		FROM (SELECT forum_id, created_at FROM messages WHERE `+authorMessage(withMembers)+`) m
+		JOIN forums f ON f.id = m.forum_id
+		GROUP BY m.forum_id, f.name
+		ORDER BY MAX(m.created_at) DESC`, authorID)
+	if err != nil {
+		return activity, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var f business.ForumActivity
+		if err := rows.Scan(&f.ForumID, &f.Title, &f.MessageCount, &f.LastPostAt); err != nil {
+			return activity, err
+		}
+		activity.MessageCount += f.MessageCount
+		activity.Forums = append(activity.Forums, f)
+	}
+	return activity, rows.Err()
+}
+
+func (r *ForumsRepo) GetUserMessagesPage(authorID int, withMembers bool, p PageRequest) ([]business.Message, bool, error) {
+	tail, args := p.keyset(authorMessage(withMembers), []interface{}{authorID})
+	rows, err := r.DB.Query(`SELECT `+messageColumns+` FROM `+messagesFrom+tail, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var messages []business.Message
+	for rows.Next() {
+		m, err := scanMessage(rows)
+		if err != nil {
+			return nil, false, err
+		}
+		messages = append(messages, *m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	messages, hasMore := trimPage(messages, p)
+	return messages, hasMore, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+// Маркеры совпадений от ts_headline; после экранирования заменяются на <mark>
+const (
+	highlightStart = "⟦"
+	highlightStop  = "⟧"
+)
+
+type SearchRepo struct {
+	DB *sql.DB
+}
+
+func NewSearchRepo(db *sql.DB) *SearchRepo {
+	return &SearchRepo{
+		DB: db,
+	}
+}
+
+// SearchParams — запрос полнотекстового поиска. Пустые поля фильтров не применяются.
+type SearchParams struct {
+	Query    string
+	Type     string // business.SearchResultForum, business.SearchResultMessage или "" для всех
+	ForumID  int
+	Author   string // только сообщения: при фильтре по автору форумы не ищутся
+	From, To time.Time
+	Limit    int
+	Offset   int
+	// WithMembers включает сообщения форумов только для участников
+	WithMembers bool
+}
+
+// Search ищет по названиям и описаниям форумов и по тексту сообщений.
+// Результаты упорядочены по релевантности, hasMore сообщает о следующей странице.
+func (r *SearchRepo) Search(p SearchParams) ([]business.SearchResult, bool, error) {
+	args := []interface{}{p.Query}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	// Общие фильтры по дате и форуму для обеих частей запроса
+	filters := func(alias, forumCol string) string {
+		var conds []string
+		if p.ForumID != 0 {
+			conds = append(conds, fmt.Sprintf("%s = %s", forumCol, arg(p.ForumID)))
+		}
+		if !p.From.IsZero() {
+			conds = append(conds, fmt.Sprintf("%s.created_at >= %s", alias, arg(p.From)))
+		}
+		if !p.To.IsZero() {
+			conds = append(conds, fmt.Sprintf("%s.created_at < %s", alias, arg(p.To)))
+		}
+		if len(conds) == 0 {
+			return ""
+		}
+		return " AND " + strings.Join(conds, " AND ")
+	}
+
+	var parts []string
+	if (p.Type == "" || p.Type == business.SearchResultForum) && p.Author == "" {
+		parts = append(parts, `
+			SELECT 'forum' AS kind, f.id, f.id AS forum_id, f.name AS forum_title, '' AS author,
+			       coalesce(f.name, '') || ' — ' || coalesce(f.description, '') AS body,
+			       ts_rank(f.search_vector, q.query) AS rank, f.created_at
+			FROM forums f, q
+			WHERE f.search_vector @@ q.query AND f.deleted_at IS NULL`+filters("f", "f.id"))
+	}
+	if p.Type == "" || p.Type == business.SearchResultMessage {
+		authorFilter := ""
+		if p.Author != "" {
+			authorFilter = " AND COALESCE(au.username, m.author) = " + arg(p.Author)
+		}
+		if !p.WithMembers {
+			authorFilter += " AND fo.visibility <> 'members'"
+		}
+		parts = append(parts, `
+			SELECT 'message' AS kind, m.id, m.forum_id, fo.name AS forum_title, COALESCE(au.username, m.author),
+			       m.content AS body,
+			       ts_rank(m.search_vector, q.query) AS rank, m.created_at
+			FROM messages m
+			JOIN forums fo ON fo.id = m.forum_id
+			LEFT JOIN users au ON au.id = m.author_id, q
+			WHERE m.search_vector @@ q.query AND m.deleted_at IS NULL AND fo.deleted_at IS NULL`+filters("m", "m.forum_id")+authorFilter)
+	}
+	if len(parts) == 0 {
+		return nil, false, nil
+	}
+
+	limit := p.Limit
+	if limit <= 0 || limit > MaxPageSize {
+		limit = DefaultPageSize
+	}
+
+	// ts_headline дорогой, поэтому считается только для строк текущей страницы
+	query := `
+		WITH q AS (SELECT websearch_to_tsquery('russian', $1) AS query)
+		SELECT r.kind, r.id, r.forum_id, r.forum_title, r.author,
+		       ts_headline('russian', r.body, q.query,
+		                   'StartSel=` + highlightStart + `, StopSel=` + highlightStop + `, MaxFragments=2, MaxWords=30, MinWords=10'),
+		       r.rank, r.created_at
+		FROM (` + strings.Join(parts, " UNION ALL ") + `
+			ORDER BY rank DESC, created_at DESC, id DESC
+			LIMIT ` + arg(limit+1) + ` OFFSET ` + arg(p.Offset) + `
+		) r, q
+		ORDER BY r.rank DESC, r.created_at DESC, r.id DESC`
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("search failed: %w", err)
+	}
+	defer rows.Close()
+
+	var results []business.SearchResult
+	for rows.Next() {
+		var res business.SearchResult
+		var snippet string
+		if err := rows.Scan(&res.Type, &res.ID, &res.ForumID, &res.ForumTitle, &res.Author, &snippet, &res.Rank, &res.CreatedAt); err != nil {
+			return nil, false, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		res.Snippet = highlight(snippet)
+		results = append(results, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(results) > limit
+	if hasMore {
+		results = results[:limit]
+	}
+	return results, hasMore, nil
+}
+
+// highlight экранирует текст и превращает маркеры совпадений в <mark>
+func highlight(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, highlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, highlightStop, "</mark>")
+}
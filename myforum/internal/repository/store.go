@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+// Интерфейсы хранилища. Их реализуют репозитории Postgres и MemoryStore,
+// обработчики и сервисы зависят только от интерфейсов.
+
+type ForumStore interface {
+	Create(f business.Forum) (int, error)
+	GetAll() ([]business.Forum, error)
+	GetAllPage(p PageRequest) ([]business.Forum, bool, error)
+	GetByID(id int) (*business.Forum, error)
+	Update(id int, f business.Forum) error
+	// Delete переносит форум в корзину; deletedBy — ID удалившего или 0
+	Delete(id int, deletedBy int) error
+}
+
+type MessageStore interface {
+	CreateMessage(msg business.Message) (int, error)
+	GetMessages(forumID int) ([]business.Message, error)
+	GetMessagesPage(forumID int, p PageRequest) ([]business.Message, bool, error)
+	GetMessageByID(messageID int) (*business.Message, error)
+	// PutMessage заменяет текст и сохраняет прежний как ревизию от editorID
+	PutMessage(messageID int, updatedContent string, editorID int) (*business.Message, error)
+	GetMessageRevisions(messageID int) ([]business.MessageRevision, error)
+	// DeleteMessage переносит сообщение в корзину; deletedBy — ID удалившего или 0
+	DeleteMessage(id int, deletedBy int) error
+}
+
+// ForumModeratorStore — модераторы отдельных форумов. Существование форума
+// и пользователя проверяет вызывающий.
+type ForumModeratorStore interface {
+	GetForumModerators(forumID int) ([]business.ForumModerator, error)
+	IsForumModerator(forumID, userID int) (bool, error)
+	AddForumModerator(forumID, userID int) error
+	RemoveForumModerator(forumID, userID int) error
+}
+
+// TrashStore — корзина мягко удаленных форумов и сообщений. Сообщения
+// удаленного форума в корзину отдельно не попадают: они скрыты вместе с ним.
+type TrashStore interface {
+	GetDeletedForums() ([]business.Forum, error)
+	GetDeletedMessages() ([]business.Message, error)
+	RestoreForum(id int) error
+	RestoreMessage(id int) error
+	// Purge окончательно удаляет то, что лежит в корзине дольше retention
+	Purge(retention time.Duration) (PurgeStats, error)
+}
+
+// PurgeStats — сколько записей окончательно удалено
+type PurgeStats struct {
+	Forums   int64
+	Messages int64
+}
+
+// ChatStore — сообщения мини-чата
+type ChatStore interface {
+	CreateGlobalMessage(msg business.GlobalMessage) (int, error)
+	GetGlobalMessages(limit int) ([]business.GlobalMessage, error)
+	GetGlobalChatHistory(limit int) ([]business.GlobalMessage, error)
+	GetGlobalChatPage(p PageRequest) ([]business.GlobalMessage, bool, error)
+	DeleteGlobalMessage(id int) error
+}
+
+type TopicStore interface {
+	Create(t business.Topic) (int, error)
+	GetByForum(forumID int) ([]business.Topic, error)
+	GetByID(id int) (*business.Topic, error)
+	Update(id int, t business.Topic) error
+	// Delete возвращает ID сообщений, которые ушли в корзину вместе с темой
+	Delete(id int, deletedBy int) ([]int, error)
+	GetMessages(topicID int) ([]business.Message, error)
+	DefaultTopicID(forumID int) (int, error)
+}
+
+// UserReader ищет пользователя по ID; пароль при этом не загружается
+type UserReader interface {
+	GetUserByID(userID int) (*business.User, error)
+}
+
+// RoleStore меняет роль пользователя; допустимость роли проверяет вызывающий
+type RoleStore interface {
+	UpdateUserRole(userID int, role string) error
+}
+
+// RevocationChecker проверяет, не отозван ли access-токен
+type RevocationChecker interface {
+	// IsAccessTokenRevoked: токен отозван по jti или выдан (issuedAt) до выхода
+	// пользователя со всех устройств
+	IsAccessTokenRevoked(jti string, userID int, issuedAt time.Time) (bool, error)
+}
+
+// TokenStore — refresh-токены сессий, отзыв access-токенов и токены из писем
+type TokenStore interface {
+	RevocationChecker
+	CreateRefreshToken(t business.RefreshToken) (int, error)
+	GetRefreshToken(tokenHash string) (*business.RefreshToken, error)
+	// RevokeRefreshToken отзывает действующий токен, иначе ErrRefreshTokenNotFound
+	RevokeRefreshToken(id, replacedBy int) error
+	RevokeUserSessions(userID int, at time.Time) error
+	RevokeAccessToken(jti string, userID int, expiresAt time.Time) error
+	DeleteExpiredTokens(before time.Time) (int64, error)
+	// Токены из писем: сброс пароля и подтверждение email
+	CreateOneTimeToken(t business.OneTimeToken) (int, error)
+	UseOneTimeToken(purpose, tokenHash string, at time.Time) (*business.OneTimeToken, error)
+}
+
+// LoginAttemptStore — счетчики неудачных входов. Запись о неудачах живет
+// window после последней из них, заблокированная — до конца блокировки.
+type LoginAttemptStore interface {
+	// GetLoginAttempts возвращает действующую на момент at запись; если ее
+	// нет, у результата нулевой Failures
+	GetLoginAttempts(scope, key string, at time.Time) (business.LoginAttempts, error)
+	// RecordLoginFailure добавляет неудачу; истекшая запись начинается заново
+	RecordLoginFailure(scope, key string, at time.Time, window time.Duration) (business.LoginAttempts, error)
+	// LockLogin блокирует вход до until; запись истекает вместе с блокировкой
+	LockLogin(scope, key string, until time.Time) error
+	// ResetLoginAttempts удаляет запись; false — ее не было
+	ResetLoginAttempts(scope, key string) (bool, error)
+	DeleteExpiredLoginAttempts(before time.Time) (int64, error)
+}
+
+type UserStore interface {
+	UserReader
+	Create(user business.User) (int, error)
+	GetByUsername(username string) (*business.User, error)
+	GetByEmail(email string) (*business.User, error)
+	UpdatePassword(userID int, hashedPassword string) error
+	MarkEmailVerified(userID int, at time.Time) error
+}
+
+// AccountStore — то, что пользователь меняет в своем аккаунте сам
+type AccountStore interface {
+	UserStore
+	UpdateProfile(userID int, p business.ProfileUpdate) error
+	// UpdateEmail сбрасывает подтверждение email; занятый адрес — ErrEmailTaken
+	UpdateEmail(userID int, email string) error
+	DeleteUser(userID int) error
+}
+
+// ProfileStore — публичные профили. Сообщения автора ищутся по author_id;
+// withMembers включает форумы для участников.
+type ProfileStore interface {
+	GetUserIDByUsername(username string) (int, error)
+	GetUserActivity(authorID int, withMembers bool) (business.UserActivity, error)
+	GetUserMessagesPage(authorID int, withMembers bool, p PageRequest) ([]business.Message, bool, error)
+}
+
+// SearchStore — полнотекстовый поиск по форумам и сообщениям
+type SearchStore interface {
+	Search(p SearchParams) ([]business.SearchResult, bool, error)
+}
+
+// Store — все, что нужно обработчикам форума
+type Store interface {
+	ForumStore
+	ForumModeratorStore
+	MessageStore
+	ChatStore
+	TrashStore
+	ProfileStore
+	UserReader
+	RoleStore
+	RevocationChecker
+}
+
+var (
+	_ Store        = (*ForumsRepo)(nil)
+	_ TopicStore   = (*TopicsRepo)(nil)
+	_ AccountStore = (*UserRepo)(nil)
+	_ TokenStore   = (*TokenRepo)(nil)
+	_ SearchStore  = (*SearchRepo)(nil)
+)
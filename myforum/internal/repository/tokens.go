@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+var (
+	// ErrRefreshTokenNotFound — токена нет или он уже отозван
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	// ErrOneTimeTokenNotFound — токена из письма нет, он истек или уже использован
+	ErrOneTimeTokenNotFound = errors.New("one-time token not found")
+)
+
+// TokenRepo хранит refresh-токены, список отозванных access-токенов и
+// одноразовые токены из писем
+type TokenRepo struct {
+	db *sql.DB
+}
+
+func NewTokenRepo(db *sql.DB) *TokenRepo {
+	return &TokenRepo{db: db}
+}
+
+func (r *TokenRepo) CreateRefreshToken(t business.RefreshToken) (int, error) {
+	var id int
+	err := r.db.QueryRow(`
+		INSERT INTO refresh_tokens (user_id, token_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`,
+		t.UserID, t.TokenHash, t.CreatedAt, t.ExpiresAt,
+	).Scan(&id)
+	return id, err
+}
+
+// GetRefreshToken ищет токен по хешу, в том числе отозванный и просроченный
+func (r *TokenRepo) GetRefreshToken(tokenHash string) (*business.RefreshToken, error) {
+	t := &business.RefreshToken{}
+	err := r.db.QueryRow(`
+		SELECT id, user_id, token_hash, created_at, expires_at, revoked_at, COALESCE(replaced_by, 0)
+		FROM refresh_tokens
+		WHERE token_hash = $1`, tokenHash,
+	).Scan(&t.ID, &t.UserID, &t.TokenHash, &t.CreatedAt, &t.ExpiresAt, &t.RevokedAt, &t.ReplacedBy)
+	if err == sql.ErrNoRows {
+		return nil, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// RevokeRefreshToken отзывает действующий токен; replacedBy — выданный
+// взамен или 0. Если токен уже отозван, возвращает ErrRefreshTokenNotFound:
+// так один токен нельзя обменять дважды.
+func (r *TokenRepo) RevokeRefreshToken(id, replacedBy int) error {
+	res, err := r.db.Exec(`
+		UPDATE refresh_tokens SET revoked_at = NOW(), replaced_by = NULLIF($2, 0)
+		WHERE id = $1 AND revoked_at IS NULL`, id, replacedBy)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrRefreshTokenNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions отзывает все refresh-токены пользователя и access-токены,
+// выданные раньше at
+func (r *TokenRepo) RevokeUserSessions(userID int, at time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`UPDATE refresh_tokens SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID, at); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`UPDATE users SET tokens_revoked_at = $2 WHERE id = $1`, userID, at)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrUserNotFound
+	}
+	return tx.Commit()
+}
+
+// RevokeAccessToken добавляет access-токен в список отозванных до его истечения
+func (r *TokenRepo) RevokeAccessToken(jti string, userID int, expiresAt time.Time) error {
+	_, err := r.db.Exec(`
+		INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		VALUES ($1, NULLIF($2, 0), $3)
+		ON CONFLICT (jti) DO NOTHING`,
+		jti, userID, expiresAt)
+	return err
+}
+
+func (r *TokenRepo) IsAccessTokenRevoked(jti string, userID int, issuedAt time.Time) (bool, error) {
+	return isAccessTokenRevoked(r.db, jti, userID, issuedAt)
+}
+
+// DeleteExpiredTokens удаляет refresh-токены, записи об отозванных
+// access-токенах и токены из писем, истекшие раньше before
+func (r *TokenRepo) DeleteExpiredTokens(before time.Time) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var total int64
+	for _, query := range []string{
+		`DELETE FROM refresh_tokens WHERE expires_at < $1`,
+		`DELETE FROM revoked_tokens WHERE expires_at < $1`,
+		`DELETE FROM user_tokens WHERE expires_at < $1`,
+	} {
+		res, err := tx.Exec(query, before)
+		if err != nil {
+			return 0, err
+		}
+		n, _ := res.RowsAffected()
+		total += n
+	}
+	return total, tx.Commit()
+}
+
+// CreateOneTimeToken сохраняет токен из письма. Прежние неиспользованные токены
+// пользователя с тем же назначением гасятся: действует только последняя ссылка.
+func (r *TokenRepo) CreateOneTimeToken(t business.OneTimeToken) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE user_tokens SET used_at = $3
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`,
+		t.UserID, t.Purpose, t.CreatedAt); err != nil {
+		return 0, err
+	}
+	var id int
+	if err := tx.QueryRow(`
+		INSERT INTO user_tokens (user_id, purpose, token_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`,
+		t.UserID, t.Purpose, t.TokenHash, t.CreatedAt, t.ExpiresAt,
+	).Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// UseOneTimeToken гасит действующий токен с назначением purpose и возвращает
+// его. Истекший, использованный или чужой токен — ErrOneTimeTokenNotFound.
+func (r *TokenRepo) UseOneTimeToken(purpose, tokenHash string, at time.Time) (*business.OneTimeToken, error) {
+	t := &business.OneTimeToken{}
+	err := r.db.QueryRow(`
+		UPDATE user_tokens SET used_at = $3
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > $3
+		RETURNING id, user_id, purpose, token_hash, created_at, expires_at, used_at`,
+		tokenHash, purpose, at,
+	).Scan(&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.CreatedAt, &t.ExpiresAt, &t.UsedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrOneTimeTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// IsAccessTokenRevoked нужен форуму для проверки токенов в каждом запросе
+func (r *ForumsRepo) IsAccessTokenRevoked(jti string, userID int, issuedAt time.Time) (bool, error) {
+	return isAccessTokenRevoked(r.DB, jti, userID, issuedAt)
+}
+
+// isAccessTokenRevoked: токен отозван по jti или выдан до выхода
+// пользователя со всех устройств
+func isAccessTokenRevoked(db *sql.DB, jti string, userID int, issuedAt time.Time) (bool, error) {
+	var revoked bool
+	err := db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+			OR EXISTS (SELECT 1 FROM users WHERE id = $2 AND tokens_revoked_at > $3)`,
+		jti, userID, issuedAt,
+	).Scan(&revoked)
+	return revoked, err
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+type TopicsRepo struct {
+	DB *sql.DB
+}
+
+func NewTopicsRepo(db *sql.DB) *TopicsRepo {
+	return &TopicsRepo{
+		DB: db,
+	}
+}
+
+func (r *TopicsRepo) Create(t business.Topic) (int, error) {
+	var exists bool
+	err := r.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM forums WHERE id = $1 AND deleted_at IS NULL)", t.ForumID).Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("forum check failed: %v", err)
+	}
+	if !exists {
+		return 0, fmt.Errorf("forum with ID %d not found", t.ForumID)
+	}
+
+	var id int
+	err = r.DB.QueryRow(`
+		INSERT INTO topics (forum_id, title, description, created_at)
+		VALUES ($1, $2, $3, DEFAULT)
+		RETURNING id`,
+		t.ForumID, t.Title, t.Desc).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("insert topic failed: %w", err)
+	}
+	return id, nil
+}
+
+// GetByForum возвращает темы форума, тема по умолчанию идет первой
+func (r *TopicsRepo) GetByForum(forumID int) ([]business.Topic, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, forum_id, title, COALESCE(description, ''), is_default, created_at
+		FROM topics
+		WHERE forum_id = $1
+		ORDER BY is_default DESC, created_at`, forumID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var topics []business.Topic
+	for rows.Next() {
+		var t business.Topic
+		if err := rows.Scan(&t.ID, &t.ForumID, &t.Title, &t.Desc, &t.IsDefault, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		topics = append(topics, t)
+	}
+	return topics, nil
+}
+
+func (r *TopicsRepo) GetByID(id int) (*business.Topic, error) {
+	var t business.Topic
+	err := r.DB.QueryRow(`
+		SELECT id, forum_id, title, COALESCE(description, ''), is_default, created_at
+		FROM topics
+		WHERE id = $1`, id,
+	).Scan(&t.ID, &t.ForumID, &t.Title, &t.Desc, &t.IsDefault, &t.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("topic not found")
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TopicsRepo) Update(id int, t business.Topic) error {
+	result, err := r.DB.Exec(
+		`UPDATE topics SET title = $1, description = $2 WHERE id = $3`,
+		t.Title, t.Desc, id,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errors.New("no topic found with the given ID")
+	}
+	return nil
+}
+
+// Delete удаляет тему. Ее сообщения уходят в корзину от имени deletedBy и
+// переносятся в тему по умолчанию, чтобы их можно было восстановить.
+// Возвращает ID сообщений, удаленных вместе с темой.
+func (r *TopicsRepo) Delete(id int, deletedBy int) ([]int, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var forumID int
+	err = tx.QueryRow(`SELECT forum_id FROM topics WHERE id = $1 AND NOT is_default FOR UPDATE`, id).Scan(&forumID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("no topic found with the given ID")
+		}
+		return nil, err
+	}
+	defaultID, err := defaultTopicID(tx, forumID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`
+		UPDATE messages
+		SET topic_id = $2, deleted_at = NOW(), deleted_by = NULLIF($3, 0)
+		WHERE topic_id = $1 AND deleted_at IS NULL
+		RETURNING id`,
+		id, defaultID, deletedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var deleted []int
+	for rows.Next() {
+		var msgID int
+		if err := rows.Scan(&msgID); err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, msgID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Уже удаленные сообщения сохраняют свои deleted_at и deleted_by
+	if _, err := tx.Exec(`UPDATE messages SET topic_id = $2 WHERE topic_id = $1`, id, defaultID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM topics WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+	return deleted, tx.Commit()
+}
+
+func (r *TopicsRepo) GetMessages(topicID int) ([]business.Message, error) {
+	rows, err := r.DB.Query(`
+		SELECT `+messageColumns+`
+		FROM `+messagesFrom+`
+		WHERE topic_id = $1 AND `+liveMessage+`
+		ORDER BY created_at`, topicID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []business.Message
+	for rows.Next() {
+		m, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *m)
+	}
+	return messages, nil
+}
+
+// DefaultTopicID возвращает тему форума по умолчанию, создавая ее при необходимости
+func (r *TopicsRepo) DefaultTopicID(forumID int) (int, error) {
+	return defaultTopicID(r.DB, forumID)
+}
+
+// execQuerier — общее у *sql.DB и *sql.Tx
+type execQuerier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func defaultTopicID(db execQuerier, forumID int) (int, error) {
+	_, err := db.Exec(`
+		INSERT INTO topics (forum_id, title, description, is_default)
+		VALUES ($1, 'Общее обсуждение', 'Сообщения без отдельной темы', TRUE)
+		ON CONFLICT (forum_id) WHERE is_default DO NOTHING`, forumID)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	err = db.QueryRow(`SELECT id FROM topics WHERE forum_id = $1 AND is_default`, forumID).Scan(&id)
+	return id, err
+}
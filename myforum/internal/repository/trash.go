@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/business"
+)
+
+var (
+	ErrForumNotInTrash   = errors.New("no deleted forum found with the given ID")
+	ErrMessageNotInTrash = errors.New("no deleted message found with the given ID")
+)
+
+// GetDeletedForums возвращает форумы из корзины, недавно удаленные первыми
+func (r *ForumsRepo) GetDeletedForums() ([]business.Forum, error) {
+	rows, err := r.DB.Query(`
+		SELECT ` + forumColumns + `, deleted_at, COALESCE(deleted_by, 0)
+		FROM forums
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	forums := []business.Forum{}
+	for rows.Next() {
+		var f business.Forum
+		if err := rows.Scan(&f.ID, &f.Title, &f.Description, &f.OwnerID, &f.Visibility, &f.CreatedAt, &f.DeletedAt, &f.DeletedBy); err != nil {
+			return nil, err
+		}
+		forums = append(forums, f)
+	}
+	return forums, rows.Err()
+}
+
+// GetDeletedMessages возвращает сообщения из корзины, недавно удаленные первыми
+func (r *ForumsRepo) GetDeletedMessages() ([]business.Message, error) {
+	rows, err := r.DB.Query(`
+		SELECT ` + messageColumns + `
+		FROM ` + messagesFrom + `
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []business.Message{}
+	for rows.Next() {
+		m, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *m)
+	}
+	return messages, rows.Err()
+}
+
+func (r *ForumsRepo) RestoreForum(id int) error {
+	result, err := r.DB.Exec(
+		`UPDATE forums SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 AND deleted_at IS NOT NULL`,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrForumNotInTrash
+	}
+	return nil
+}
+
+// RestoreMessage возвращает сообщение; если его форум тоже в корзине,
+// сообщение появится вместе с форумом
+func (r *ForumsRepo) RestoreMessage(id int) error {
+	result, err := r.DB.Exec(
+		`UPDATE messages SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 AND deleted_at IS NOT NULL`,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrMessageNotInTrash
+	}
+	return nil
+}
+
+// Purge окончательно удаляет записи, пролежавшие в корзине дольше retention.
+// Сообщения удаляются первыми: внешний ключ на форум не каскадный.
+func (r *ForumsRepo) Purge(retention time.Duration) (PurgeStats, error) {
+	var stats PurgeStats
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return stats, fmt.Errorf("purge failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Срок считается в базе, так же как и deleted_at
+	cutoff := `NOW() - make_interval(secs => $1)`
+	secs := retention.Seconds()
+
+	result, err := tx.Exec(`
+		DELETE FROM messages
+		WHERE deleted_at < `+cutoff+`
+		   OR forum_id IN (SELECT id FROM forums WHERE deleted_at < `+cutoff+`)`, secs)
+	if err != nil {
+		return stats, fmt.Errorf("purge messages failed: %w", err)
+	}
+	stats.Messages, _ = result.RowsAffected()
+
+	result, err = tx.Exec(`DELETE FROM forums WHERE deleted_at < `+cutoff, secs)
+	if err != nil {
+		return stats, fmt.Errorf("purge forums failed: %w", err)
+	}
+	stats.Forums, _ = result.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return PurgeStats{}, fmt.Errorf("purge failed: %w", err)
+	}
+	return stats, nil
+}
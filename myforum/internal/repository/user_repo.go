@@ -6,6 +6,13 @@ import (
 	"time"
 
 	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	// ErrEmailTaken — email уже занят другим пользователем
+	ErrEmailTaken = errors.New("email already exists")
 )
 
 type UserRepo struct {
@@ -42,7 +49,7 @@ func (r *UserRepo) Create(user business.User) (int, error) {
 
 func (r *UserRepo) GetByUsername(username string) (*business.User, error) {
 	query := `
-		SELECT id, username, email, password, role, created_at, updated_at
+		SELECT id, username, email, password, role, created_at, updated_at, email_verified_at, bio, avatar_url
 		FROM users
 		WHERE username = $1`
 
@@ -55,10 +62,13 @@ func (r *UserRepo) GetByUsername(username string) (*business.User, error) {
 		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.EmailVerifiedAt,
+		&user.Bio,
+		&user.AvatarURL,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 	if err != nil {
 		return nil, err
@@ -69,7 +79,7 @@ func (r *UserRepo) GetByUsername(username string) (*business.User, error) {
 
 func (r *UserRepo) GetByEmail(email string) (*business.User, error) {
 	query := `
-		SELECT id, username, email, password, role, created_at, updated_at
+		SELECT id, username, email, password, role, created_at, updated_at, email_verified_at, bio, avatar_url
 		FROM users
 		WHERE email = $1`
 
@@ -82,10 +92,13 @@ func (r *UserRepo) GetByEmail(email string) (*business.User, error) {
 		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.EmailVerifiedAt,
+		&user.Bio,
+		&user.AvatarURL,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 	if err != nil {
 		return nil, err
@@ -96,7 +109,7 @@ func (r *UserRepo) GetByEmail(email string) (*business.User, error) {
 
 func (r *UserRepo) GetUserByID(userID int) (*business.User, error) {
 	query := `
-        SELECT id, username, email, role, created_at, updated_at
+        SELECT id, username, email, role, created_at, updated_at, email_verified_at, bio, avatar_url
         FROM users
         WHERE id = $1`
 
@@ -108,10 +121,13 @@ func (r *UserRepo) GetUserByID(userID int) (*business.User, error) {
 		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.EmailVerifiedAt,
+		&user.Bio,
+		&user.AvatarURL,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 	if err != nil {
 		return nil, err
@@ -129,3 +145,88 @@ func (r *UserRepo) UpdatePassword(userID int, hashedPassword string) error {
 	_, err := r.db.Exec(query, hashedPassword, time.Now(), userID)
 	return err
 }
+
+// MarkEmailVerified отмечает email пользователя подтвержденным
+func (r *UserRepo) MarkEmailVerified(userID int, at time.Time) error {
+	res, err := r.db.Exec(
+		`UPDATE users SET email_verified_at = $1, updated_at = NOW() WHERE id = $2`,
+		at, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateProfile меняет заданные поля профиля
+func (r *UserRepo) UpdateProfile(userID int, p business.ProfileUpdate) error {
+	res, err := r.db.Exec(`
+		UPDATE users
+		SET bio = COALESCE($2, bio), avatar_url = COALESCE($3, avatar_url), updated_at = NOW()
+		WHERE id = $1`,
+		userID, p.Bio, p.AvatarURL)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateEmail меняет email; новый адрес считается неподтвержденным
+func (r *UserRepo) UpdateEmail(userID int, email string) error {
+	res, err := r.db.Exec(`
+		UPDATE users SET email = $2, email_verified_at = NULL, updated_at = NOW()
+		WHERE id = $1`,
+		userID, email)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrEmailTaken
+	}
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// DeleteUser удаляет аккаунт. Сообщения и цитаты пользователя остаются, но
+// автором в них становится business.DeletedAuthor; остальное (author_id,
+// токены, модерация, владение форумами) снимают внешние ключи.
+func (r *UserRepo) DeleteUser(userID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Имя меняется до удаления: потом author_id станет NULL
+	for _, query := range []string{
+		`UPDATE messages SET author = $2 WHERE author_id = $1`,
+		`UPDATE chat_messages SET author = $2 WHERE author_id = $1`,
+	} {
+		if _, err := tx.Exec(query, userID, business.DeletedAuthor); err != nil {
+			return err
+		}
+	}
+
+	var username string
+	err = tx.QueryRow(`DELETE FROM users WHERE id = $1 RETURNING username`, userID).Scan(&username)
+	if err == sql.ErrNoRows {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	// Цитата — снимок, в ней есть только имя
+	_, err = tx.Exec(`UPDATE messages SET quote_author = $2 WHERE quote_author = $1`, username, business.DeletedAuthor)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
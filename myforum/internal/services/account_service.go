@@ -0,0 +1,208 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	netmail "net/mail"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/mail"
+	"github.com/jaxxiy/myforum/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Ограничения полей профиля
+const (
+	maxBioLength       = 1000
+	maxAvatarURLLength = 500
+)
+
+var (
+	// ErrInvalidAccountUpdate — поле профиля не прошло проверку; текст ошибки
+	// объясняет, какое
+	ErrInvalidAccountUpdate = errors.New("invalid account update")
+	// ErrWrongPassword — текущий пароль не подошел
+	ErrWrongPassword = errors.New("current password is incorrect")
+)
+
+// AccountOptions — отзыв сессий, письма и ограничение попыток пароля
+type AccountOptions struct {
+	// Tokens отзывает сессии после смены пароля и хранит ссылки из писем
+	Tokens repository.TokenStore
+	// Mailer отправляет ссылку подтверждения нового email; nil — письма не отправляются
+	Mailer               mail.Mailer
+	PublicURL            string
+	EmailVerificationTTL time.Duration
+	// Guard считает неверные текущие пароли вместе с неудачными входами; nil — без ограничений
+	Guard *LoginGuard
+}
+
+// AccountService — изменения, которые пользователь делает в своем аккаунте:
+// профиль, email, пароль и удаление
+type AccountService struct {
+	users repository.AccountStore
+	opts  AccountOptions
+}
+
+func NewAccountService(users repository.AccountStore, opts AccountOptions) *AccountService {
+	return &AccountService{users: users, opts: opts}
+}
+
+// Update применяет PATCH /api/me и возвращает обновленного пользователя; ip —
+// адрес клиента для ограничения перебора пароля. Все поля, включая занятость
+// email, проверяются до того, как что-либо будет изменено. Смена пароля
+// завершает все сессии пользователя, в том числе текущую.
+func (s *AccountService) Update(userID int, req business.UpdateAccountRequest, ip string) (*business.User, error) {
+	user, err := s.users.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := business.ProfileUpdate{Bio: req.Bio, AvatarURL: req.AvatarURL}
+	if req.Bio != nil {
+		bio := strings.TrimSpace(*req.Bio)
+		if utf8.RuneCountInString(bio) > maxBioLength {
+			return nil, fmt.Errorf("%w: bio is longer than %d characters", ErrInvalidAccountUpdate, maxBioLength)
+		}
+		profile.Bio = &bio
+	}
+	if req.AvatarURL != nil {
+		avatar := strings.TrimSpace(*req.AvatarURL)
+		if err := validateAvatarURL(avatar); err != nil {
+			return nil, err
+		}
+		profile.AvatarURL = &avatar
+	}
+
+	var email string
+	if req.Email != nil {
+		addr, err := netmail.ParseAddress(strings.TrimSpace(*req.Email))
+		if err != nil || addr.Name != "" {
+			return nil, fmt.Errorf("%w: invalid email", ErrInvalidAccountUpdate)
+		}
+		if addr.Address != user.Email {
+			email = addr.Address
+		}
+	}
+	var hashedPassword []byte
+	if req.NewPassword != nil {
+		if *req.NewPassword == "" {
+			return nil, fmt.Errorf("%w: new password is empty", ErrInvalidAccountUpdate)
+		}
+		if hashedPassword, err = bcrypt.GenerateFromPassword([]byte(*req.NewPassword), bcrypt.DefaultCost); err != nil {
+			return nil, err
+		}
+	}
+	// Email и пароль защищают аккаунт, поэтому их меняют только зная пароль
+	if email != "" || hashedPassword != nil {
+		if err := s.checkPassword(user.Username, req.CurrentPassword, ip); err != nil {
+			return nil, err
+		}
+	}
+	// Занятость адреса проверяется после пароля, чтобы по ответу нельзя было
+	// перебирать чужие адреса
+	if email != "" {
+		if _, err := s.users.GetByEmail(email); err == nil {
+			return nil, repository.ErrEmailTaken
+		} else if !errors.Is(err, repository.ErrUserNotFound) {
+			return nil, err
+		}
+	}
+
+	// Email меняется первым: только он может не записаться, если адрес успели
+	// занять параллельным запросом
+	if email != "" {
+		if err := s.users.UpdateEmail(userID, email); err != nil {
+			return nil, err
+		}
+	}
+	if hashedPassword != nil {
+		if err := s.users.UpdatePassword(userID, string(hashedPassword)); err != nil {
+			return nil, err
+		}
+		// Кто бы ни знал старый пароль, его сессии больше не действуют
+		if err := s.opts.Tokens.RevokeUserSessions(userID, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	if profile.Bio != nil || profile.AvatarURL != nil {
+		if err := s.users.UpdateProfile(userID, profile); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.users.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if email != "" {
+		// Email уже изменен: письмо можно запросить повторно
+		if err := sendVerification(s.opts.Tokens, s.opts.Mailer, s.opts.PublicURL, s.opts.EmailVerificationTTL, *updated); err != nil {
+			log.Printf("Ошибка отправки письма подтверждения пользователю %d: %v", userID, err)
+		}
+	}
+	return updated, nil
+}
+
+// Delete удаляет аккаунт после проверки пароля. Сообщения пользователя
+// остаются с автором business.DeletedAuthor.
+func (s *AccountService) Delete(userID int, password, ip string) error {
+	user, err := s.users.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkPassword(user.Username, password, ip); err != nil {
+		return err
+	}
+	return s.users.DeleteUser(userID)
+}
+
+// checkPassword сверяет пароль с хешем; GetUserByID хеш не загружает.
+// Неверный пароль считается неудачным входом, иначе украденный access-токен
+// позволял бы подбирать пароль без ограничений.
+func (s *AccountService) checkPassword(username, password, ip string) error {
+	guard := s.opts.Guard
+	if guard != nil {
+		if err := guard.Check(username, ip); err != nil {
+			return err
+		}
+	}
+	user, err := s.users.GetByUsername(username)
+	if err != nil {
+		return err
+	}
+	if password == "" || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		if guard != nil {
+			if err := guard.Failure(username, ip); err != nil {
+				log.Printf("Ошибка учета неверного пароля %q с %s: %v", username, ip, err)
+			}
+		}
+		return ErrWrongPassword
+	}
+	if guard != nil {
+		if err := guard.Success(username); err != nil {
+			log.Printf("Ошибка сброса счетчика входов %q: %v", username, err)
+		}
+	}
+	return nil
+}
+
+// validateAvatarURL: пустая строка убирает аватар, иначе нужна ссылка http(s)
+func validateAvatarURL(avatar string) error {
+	if avatar == "" {
+		return nil
+	}
+	if len(avatar) > maxAvatarURLLength {
+		return fmt.Errorf("%w: avatar_url is longer than %d characters", ErrInvalidAccountUpdate, maxAvatarURLLength)
+	}
+	u, err := url.Parse(avatar)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: avatar_url must be an http(s) URL", ErrInvalidAccountUpdate)
+	}
+	return nil
+}
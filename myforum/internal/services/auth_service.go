@@ -1,27 +1,78 @@
 package services
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
-	"os"
+	"fmt"
+	"log"
+	"net/url"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/mail"
+	"github.com/jaxxiy/myforum/internal/rbac"
 	"github.com/jaxxiy/myforum/internal/repository"
+	"github.com/jaxxiy/myforum/pkg/jwt"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	// ErrInvalidCredentials — нет такого пользователя или пароль неверный
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrUserBanned — пароль верный, но пользователь заблокирован
+	ErrUserBanned = errors.New("user is banned")
+	// ErrPermissionDenied — у пользователя нет нужного права
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrInvalidRefreshToken — refresh-токена нет, он истек или отозван
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrInvalidOneTimeToken — ссылки из письма нет, она истекла или уже использована
+	ErrInvalidOneTimeToken = errors.New("invalid or expired link")
+	// ErrPasswordRequired — новый пароль пустой
+	ErrPasswordRequired = errors.New("password is required")
+	// ErrEmailAlreadyVerified — повторное письмо подтверждения не нужно
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+)
+
+// AuthOptions — сроки жизни токенов и отправка писем
+type AuthOptions struct {
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	// Сколько действуют ссылки из писем
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	// Mailer отправляет ссылки; nil — письма не отправляются
+	Mailer mail.Mailer
+	// PublicURL — адрес сервиса авторизации, на который ведут ссылки
+	PublicURL string
+	// Guard ограничивает неудачные входы; nil — без ограничений
+	Guard *LoginGuard
+}
+
 type AuthService struct {
-	userRepo *repository.UserRepo
+	userRepo   repository.UserStore
+	tokens     repository.TokenStore
+	jwtManager *jwt.Manager
+	opts       AuthOptions
 }
 
-func NewAuthService(userRepo *repository.UserRepo) *AuthService {
+func NewAuthService(userRepo repository.UserStore, tokens repository.TokenStore, jwtManager *jwt.Manager, opts AuthOptions) *AuthService {
 	return &AuthService{
-		userRepo: userRepo,
+		userRepo:   userRepo,
+		tokens:     tokens,
+		jwtManager: jwtManager,
+		opts:       opts,
 	}
 }
 
 func (s *AuthService) Register(req business.RegisterRequest) (*business.AuthResponse, error) {
+	// Под этим именем показываются сообщения удаленных аккаунтов
+	if req.Username == business.DeletedAuthor {
+		return nil, errors.New("username is reserved")
+	}
+
 	// Check if username already exists
 	if _, err := s.userRepo.GetByUsername(req.Username); err == nil {
 		return nil, errors.New("username already exists")
@@ -43,7 +94,7 @@ func (s *AuthService) Register(req business.RegisterRequest) (*business.AuthResp
 		Username:  req.Username,
 		Email:     req.Email,
 		Password:  string(hashedPassword),
-		Role:      "user", // всегда user
+		Role:      string(rbac.DefaultRole), // роль меняет только администратор
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -54,97 +105,382 @@ func (s *AuthService) Register(req business.RegisterRequest) (*business.AuthResp
 	}
 	user.ID = userID
 
-	// Generate JWT token
-	token, err := s.generateToken(user)
-	if err != nil {
-		return nil, err
+	// Регистрация не зависит от почты: письмо можно запросить повторно
+	if err := s.sendVerification(user); err != nil {
+		log.Printf("Ошибка отправки письма подтверждения пользователю %d: %v", user.ID, err)
 	}
-
-	return &business.AuthResponse{
-		Token: token,
-		User:  user,
-	}, nil
+	return s.issueTokens(user)
 }
 
-func (s *AuthService) Login(req business.LoginRequest) (*business.AuthResponse, error) {
+// Login проверяет имя и пароль; ip — адрес клиента для ограничения перебора.
+// Если попыток было слишком много, возвращает *LoginLockedError.
+func (s *AuthService) Login(req business.LoginRequest, ip string) (*business.AuthResponse, error) {
+	// Ограничение проверяется до bcrypt, чтобы перебор не нагружал сервис
+	if s.opts.Guard != nil {
+		if err := s.opts.Guard.Check(req.Username, ip); err != nil {
+			return nil, err
+		}
+	}
+
 	// Get user by username
 	user, err := s.userRepo.GetByUsername(req.Username)
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		s.loginFailed(req.Username, ip)
+		return nil, ErrInvalidCredentials
 	}
 
 	// Check password
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		s.loginFailed(req.Username, ip)
+		return nil, ErrInvalidCredentials
+	}
+	if s.opts.Guard != nil {
+		if err := s.opts.Guard.Success(req.Username); err != nil {
+			log.Printf("Ошибка сброса счетчика входов %q: %v", req.Username, err)
+		}
+	}
+	if user.Role == string(rbac.Banned) {
+		return nil, ErrUserBanned
+	}
+
+	return s.issueTokens(*user)
+}
+
+// loginFailed учитывает неудачный вход; ошибка счетчика не меняет ответ клиенту
+func (s *AuthService) loginFailed(username, ip string) {
+	if s.opts.Guard == nil {
+		return
+	}
+	if err := s.opts.Guard.Failure(username, ip); err != nil {
+		log.Printf("Ошибка учета неудачного входа %q с %s: %v", username, ip, err)
+	}
+}
+
+// UnlockLogin снимает блокировку входа по имени и/или IP; нужно право login.unlock
+func (s *AuthService) UnlockLogin(admin *business.User, req business.UnlockLoginRequest) error {
+	if !rbac.Can(admin, rbac.LoginUnlock) {
+		return ErrPermissionDenied
+	}
+	if s.opts.Guard == nil {
+		return nil
+	}
+	return s.opts.Guard.Unlock(admin, req)
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов; старый refresh-токен
+// при этом отзывается
+func (s *AuthService) Refresh(refreshToken string) (*business.AuthResponse, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+	stored, err := s.tokens.GetRefreshToken(hashToken(refreshToken))
+	if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.RevokedAt != nil {
+		// Уже обмененный токен предъявили снова: его могли украсть, поэтому
+		// завершаем все сессии пользователя
+		if stored.ReplacedBy != 0 {
+			if err := s.tokens.RevokeUserSessions(stored.UserID, time.Now()); err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+				return nil, err
+			}
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.GetUserByID(stored.UserID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == string(rbac.Banned) {
+		return nil, ErrUserBanned
 	}
 
-	// Generate JWT token
 	token, err := s.generateToken(*user)
 	if err != nil {
 		return nil, err
 	}
+	refreshToken, nextID, err := s.newRefreshToken(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokens.RevokeRefreshToken(stored.ID, nextID); err != nil {
+		// Токен успели обменять параллельным запросом: новая пара не нужна
+		s.tokens.RevokeRefreshToken(nextID, 0)
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	return s.authResponse(*user, token, refreshToken), nil
+}
 
+// Logout завершает одну сессию: отзывает refresh-токен и access-токен.
+// Любой из них может быть пустым или уже недействительным.
+func (s *AuthService) Logout(accessToken, refreshToken string) error {
+	if refreshToken != "" {
+		stored, err := s.tokens.GetRefreshToken(hashToken(refreshToken))
+		if err == nil {
+			err = s.tokens.RevokeRefreshToken(stored.ID, 0)
+		}
+		if err != nil && !errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return err
+		}
+	}
+
+	if accessToken != "" {
+		claims, err := s.jwtManager.Parse(accessToken)
+		if err != nil {
+			// Просроченный или чужой токен отзывать не нужно
+			return nil
+		}
+		return s.revokeAccessToken(claims)
+	}
+	return nil
+}
+
+// LogoutAll завершает все сессии владельца access-токена
+func (s *AuthService) LogoutAll(accessToken string) error {
+	claims, err := s.jwtManager.ParseActive(accessToken, s.tokens)
+	if err != nil {
+		return err
+	}
+
+	// Время отзыва хранится с точностью до секунды, как iat в токенах, поэтому
+	// токен, с которым пришел запрос, отзываем еще и по jti
+	if err := s.tokens.RevokeUserSessions(claims.UserID, time.Now().Truncate(time.Second)); err != nil {
+		return err
+	}
+	return s.revokeAccessToken(claims)
+}
+
+// issueTokens выдает пользователю access-токен и новый refresh-токен
+func (s *AuthService) issueTokens(user business.User) (*business.AuthResponse, error) {
+	token, err := s.generateToken(user)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, _, err := s.newRefreshToken(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.authResponse(user, token, refreshToken), nil
+}
+
+func (s *AuthService) authResponse(user business.User, token, refreshToken string) *business.AuthResponse {
 	return &business.AuthResponse{
-		Token: token,
-		User:  *user,
-	}, nil
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.opts.AccessTTL.Seconds()),
+		User:         user,
+	}
+}
+
+// newRefreshToken сохраняет хеш нового refresh-токена и возвращает сам токен и его ID
+func (s *AuthService) newRefreshToken(userID int) (string, int, error) {
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return "", 0, err
+	}
+	now := time.Now()
+	id, err := s.tokens.CreateRefreshToken(business.RefreshToken{
+		UserID:    userID,
+		TokenHash: hashToken(refreshToken),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.opts.RefreshTTL),
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return refreshToken, id, nil
 }
 
 func (s *AuthService) generateToken(user business.User) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id":  user.ID,
-		"username": user.Username,
-		"role":     user.Role,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(),
+	return s.jwtManager.Issue(jwt.Claims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+	}, s.opts.AccessTTL)
+}
+
+// ForgotPassword отправляет ссылку сброса пароля на email. Неизвестный адрес
+// не считается ошибкой, чтобы по ответу нельзя было проверить, есть ли аккаунт.
+func (s *AuthService) ForgotPassword(email string) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := newOneTimeToken(s.tokens, user.ID, business.PurposePasswordReset, s.opts.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+	return send(s.opts.Mailer, mail.Message{
+		To:      user.Email,
+		Subject: "Сброс пароля на MyForum",
+		Body: fmt.Sprintf("Чтобы задать новый пароль для %s, откройте ссылку:\n\n%s\n\n"+
+			"Ссылка действует %s. Если вы не запрашивали сброс, просто удалите письмо.",
+			user.Username, link(s.opts.PublicURL, "/auth/reset-password", token), s.opts.PasswordResetTTL),
 	})
+}
 
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "your-secret-key"
+// ResetPassword задает новый пароль по ссылке из письма и завершает все
+// сессии пользователя
+func (s *AuthService) ResetPassword(token, password string) error {
+	if password == "" {
+		return ErrPasswordRequired
+	}
+	used, err := s.useOneTimeToken(business.PurposePasswordReset, token)
+	if err != nil {
+		return err
 	}
 
-	tokenString, err := token.SignedString([]byte(jwtSecret))
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return "", err
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(used.UserID, string(hashedPassword)); err != nil {
+		return err
+	}
+	// Письмо дошло до владельца адреса — адрес тем самым подтвержден
+	if err := s.userRepo.MarkEmailVerified(used.UserID, time.Now()); err != nil {
+		return err
 	}
+	// Кто бы ни знал старый пароль, его сессии больше не действуют. В отличие от
+	// LogoutAll время не округляется: отзываются и токены, выданные в эту же секунду.
+	return s.tokens.RevokeUserSessions(used.UserID, time.Now())
+}
 
-	return tokenString, nil
+// VerifyEmail подтверждает email по ссылке из письма
+func (s *AuthService) VerifyEmail(token string) error {
+	used, err := s.useOneTimeToken(business.PurposeEmailVerification, token)
+	if err != nil {
+		return err
+	}
+	return s.userRepo.MarkEmailVerified(used.UserID, time.Now())
 }
 
-func (s *AuthService) ValidateToken(tokenString string) (*business.User, error) {
-	// Get JWT secret from environment variable
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "your-secret-key" // Default secret key for development
+// ResendVerification повторно отправляет письмо подтверждения владельцу access-токена
+func (s *AuthService) ResendVerification(accessToken string) error {
+	user, err := s.ValidateToken(accessToken)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
 	}
+	return s.sendVerification(*user)
+}
 
-	// Parse token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(jwtSecret), nil
+func (s *AuthService) sendVerification(user business.User) error {
+	return sendVerification(s.tokens, s.opts.Mailer, s.opts.PublicURL, s.opts.EmailVerificationTTL, user)
+}
+
+// sendVerification отправляет на user.Email ссылку подтверждения адреса.
+// Ее же отправляет AccountService после смены email.
+func sendVerification(tokens repository.TokenStore, mailer mail.Mailer, publicURL string, ttl time.Duration, user business.User) error {
+	token, err := newOneTimeToken(tokens, user.ID, business.PurposeEmailVerification, ttl)
+	if err != nil {
+		return err
+	}
+	return send(mailer, mail.Message{
+		To:      user.Email,
+		Subject: "Подтвердите email на MyForum",
+		Body: fmt.Sprintf("Чтобы подтвердить адрес для %s, откройте ссылку:\n\n%s\n\nСсылка действует %s.",
+			user.Username, link(publicURL, "/auth/verify-email", token), ttl),
 	})
+}
+
+func send(mailer mail.Mailer, msg mail.Message) error {
+	if mailer == nil {
+		return nil
+	}
+	return mailer.Send(msg)
+}
+
+// link — ссылка на страницу сервиса авторизации с токеном в параметре
+func link(publicURL, path, token string) string {
+	return publicURL + path + "?token=" + url.QueryEscape(token)
+}
 
+// newOneTimeToken сохраняет хеш токена для письма и возвращает сам токен
+func newOneTimeToken(tokens repository.TokenStore, userID int, purpose string, ttl time.Duration) (string, error) {
+	token, err := randomToken(32)
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	now := time.Now()
+	_, err = tokens.CreateOneTimeToken(business.OneTimeToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *AuthService) useOneTimeToken(purpose, token string) (*business.OneTimeToken, error) {
+	if token == "" {
+		return nil, ErrInvalidOneTimeToken
+	}
+	used, err := s.tokens.UseOneTimeToken(purpose, hashToken(token), time.Now())
+	if errors.Is(err, repository.ErrOneTimeTokenNotFound) {
+		return nil, ErrInvalidOneTimeToken
 	}
+	return used, err
+}
 
-	// Validate token
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID := int(claims["user_id"].(float64))
-		username := claims["username"].(string)
+// JWKS — открытые ключи, которыми проверяются выданные токены
+func (s *AuthService) JWKS() jwt.JWKS {
+	return s.jwtManager.JWKS()
+}
 
-		// Get user from database
-		user, err := s.userRepo.GetByUsername(username)
-		if err != nil {
-			return nil, err
-		}
+// ValidateToken проверяет access-токен и возвращает его владельца
+func (s *AuthService) ValidateToken(tokenString string) (*business.User, error) {
+	claims, err := s.jwtManager.ParseActive(tokenString, s.tokens)
+	if err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetUserByID(claims.UserID)
+}
 
-		if user.ID != userID {
-			return nil, errors.New("invalid token")
-		}
+// revokeAccessToken вносит токен в список отозванных до его истечения
+func (s *AuthService) revokeAccessToken(claims *jwt.Claims) error {
+	if claims.ID == "" {
+		return nil
+	}
+	return s.tokens.RevokeAccessToken(claims.ID, claims.UserID, claims.ExpiresAt.Time)
+}
 
-		return user, nil
+// randomToken возвращает n случайных байт в base64url
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
 
-	return nil, errors.New("invalid token")
+// hashToken — в базе хранится только SHA-256 от refresh-токена и токенов из писем
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
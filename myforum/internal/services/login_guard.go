@@ -0,0 +1,161 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jaxxiy/myforum/internal/business"
+	"github.com/jaxxiy/myforum/internal/repository"
+)
+
+// ErrNothingToUnlock — в запросе разблокировки нет ни имени, ни IP
+var ErrNothingToUnlock = errors.New("username or ip is required")
+
+// LoginLimits — когда замедлять и блокировать вход
+type LoginLimits struct {
+	// Сколько неудач подряд блокируют вход; 0 — не блокировать
+	MaxFailures      int
+	MaxFailuresPerIP int
+	// Неудачи старше Window забываются
+	Window time.Duration
+	// Пауза после первой неудачи под одним именем; после каждой следующей
+	// удваивается, но не превышает LockoutDuration. 0 — без пауз
+	Delay           time.Duration
+	LockoutDuration time.Duration
+}
+
+// LoginLockedError — вход временно запрещен, повторить можно через RetryAfter
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return "too many failed login attempts, try again later"
+}
+
+// LoginGuard защищает вход от перебора паролей: считает неудачи по имени
+// пользователя и по IP, после неудач под одним именем требует паузу, а после
+// MaxFailures блокирует вход. Блокировки и разблокировки пишутся в аудит-лог.
+type LoginGuard struct {
+	store  repository.LoginAttemptStore
+	limits LoginLimits
+	audit  *log.Logger
+}
+
+// NewLoginGuard создает защиту входа; audit == nil — аудит идет в стандартный лог
+func NewLoginGuard(store repository.LoginAttemptStore, limits LoginLimits, audit *log.Logger) *LoginGuard {
+	if audit == nil {
+		audit = log.New(log.Writer(), "audit: ", log.Flags())
+	}
+	return &LoginGuard{store: store, limits: limits, audit: audit}
+}
+
+// loginCounter — счетчик, по которому ограничивается вход
+type loginCounter struct {
+	scope string
+	key   string
+	max   int
+}
+
+func (g *LoginGuard) counters(username, ip string) []loginCounter {
+	var counters []loginCounter
+	// Регистр не важен, иначе перебор обходился бы сменой регистра
+	if username = strings.ToLower(strings.TrimSpace(username)); username != "" {
+		counters = append(counters, loginCounter{business.LoginScopeUsername, username, g.limits.MaxFailures})
+	}
+	if ip != "" {
+		counters = append(counters, loginCounter{business.LoginScopeIP, ip, g.limits.MaxFailuresPerIP})
+	}
+	return counters
+}
+
+// Check вызывается до проверки пароля и возвращает *LoginLockedError, если
+// вход под username с адреса ip сейчас запрещен
+func (g *LoginGuard) Check(username, ip string) error {
+	now := time.Now()
+	var wait time.Duration
+	for _, c := range g.counters(username, ip) {
+		a, err := g.store.GetLoginAttempts(c.scope, c.key, now)
+		if err != nil {
+			return err
+		}
+		if d := g.retryAfter(a, now); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return &LoginLockedError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// retryAfter — сколько осталось ждать до следующей попытки. Пауза растет
+// только по имени: за одним IP бывает много пользователей.
+func (g *LoginGuard) retryAfter(a business.LoginAttempts, now time.Time) time.Duration {
+	if a.LockedUntil != nil {
+		return a.LockedUntil.Sub(now)
+	}
+	if a.Scope != business.LoginScopeUsername || a.Failures == 0 || g.limits.Delay <= 0 {
+		return 0
+	}
+	delay := g.limits.Delay
+	for i := 1; i < a.Failures && delay < g.limits.LockoutDuration; i++ {
+		delay *= 2
+	}
+	if delay > g.limits.LockoutDuration {
+		delay = g.limits.LockoutDuration
+	}
+	return a.LastFailureAt.Add(delay).Sub(now)
+}
+
+// Failure учитывает неудачный вход и блокирует вход, если неудач набралось
+// слишком много
+func (g *LoginGuard) Failure(username, ip string) error {
+	now := time.Now()
+	for _, c := range g.counters(username, ip) {
+		a, err := g.store.RecordLoginFailure(c.scope, c.key, now, g.limits.Window)
+		if err != nil {
+			return err
+		}
+		if c.max == 0 || a.Failures < c.max || a.LockedUntil != nil {
+			continue
+		}
+		until := now.Add(g.limits.LockoutDuration)
+		if err := g.store.LockLogin(c.scope, c.key, until); err != nil {
+			return err
+		}
+		g.audit.Printf("login locked: %s=%q failures=%d until=%s", c.scope, c.key, a.Failures, until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Success сбрасывает счетчик имени. Счетчик IP остается: иначе перебор
+// с одного адреса можно было бы прерывать входом в свой аккаунт.
+func (g *LoginGuard) Success(username string) error {
+	for _, c := range g.counters(username, "") {
+		if _, err := g.store.ResetLoginAttempts(c.scope, c.key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unlock снимает блокировку и обнуляет счетчики имени и IP из запроса
+func (g *LoginGuard) Unlock(admin *business.User, req business.UnlockLoginRequest) error {
+	counters := g.counters(req.Username, strings.TrimSpace(req.IP))
+	if len(counters) == 0 {
+		return ErrNothingToUnlock
+	}
+	for _, c := range counters {
+		found, err := g.store.ResetLoginAttempts(c.scope, c.key)
+		if err != nil {
+			return err
+		}
+		if found {
+			g.audit.Printf("login unlocked: %s=%q by %s (id %d)", c.scope, c.key, admin.Username, admin.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package ws
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// BearerProtocol — подпротокол для передачи JWT из браузера, где у WebSocket
+// нельзя задать заголовок: new WebSocket(url, ["bearer", token]).
+const BearerProtocol = "bearer"
+
+// TokenFromRequest достает JWT из заголовка Authorization, параметра token
+// или заголовка Sec-WebSocket-Protocol. Пустая строка — токена нет.
+func TokenFromRequest(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		return strings.TrimPrefix(h, "Bearer ")
+	}
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token
+	}
+
+	protocols := websocket.Subprotocols(r)
+	for i, p := range protocols {
+		if p == BearerProtocol && i+1 < len(protocols) {
+			return protocols[i+1]
+		}
+	}
+	return ""
+}
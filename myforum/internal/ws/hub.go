@@ -0,0 +1,275 @@
+// Package ws — WebSocket-хаб форума: комнаты, очередь исходящих сообщений
+// и одна пишущая горутина на соединение, как требует gorilla/websocket.
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/jaxxiy/myforum/internal/broadcast"
+)
+
+const (
+	// Время на запись одного сообщения клиенту
+	writeWait = 10 * time.Second
+	// Клиент должен ответить на ping за это время
+	pongWait = 60 * time.Second
+	// Ping отправляется чаще, чем истекает pongWait
+	pingPeriod = pongWait * 9 / 10
+	// Максимальный размер входящего сообщения
+	maxMessageSize = 64 * 1024
+	// Очередь исходящих сообщений; клиент, который ее переполнил, отключается
+	sendBuffer = 256
+)
+
+// GlobalChatRoom — комната мини-чата
+const GlobalChatRoom = "global"
+
+// ForumRoom возвращает комнату событий форума
+func ForumRoom(forumID int) string {
+	return fmt.Sprintf("forum:%d", forumID)
+}
+
+// Hub хранит соединения по комнатам и рассылает им сообщения.
+// Broadcast доставляет только своим соединениям, Publish — через broadcaster
+// соединениям всех экземпляров сервера.
+type Hub struct {
+	mu          sync.RWMutex
+	rooms       map[string]map[*Client]struct{}
+	upgrader    websocket.Upgrader
+	broadcaster broadcast.Broadcaster
+}
+
+func NewHub(b broadcast.Broadcaster) *Hub {
+	h := &Hub{
+		rooms:       make(map[string]map[*Client]struct{}),
+		broadcaster: b,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// Клиент с токеном в подпротоколе ждет его в ответе, иначе браузер закроет соединение
+			Subprotocols: []string{BearerProtocol},
+			CheckOrigin: func(r *http.Request) bool {
+				return true // Для разработки
+			},
+		},
+	}
+	b.Subscribe(h.Broadcast)
+	return h
+}
+
+// Client — соединение в одной комнате. Писать в соединение может только
+// writePump, остальные ставят сообщения в очередь через Send.
+type Client struct {
+	hub  *Hub
+	conn *websocket.Conn
+	room string
+
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Handlers — обработчики событий соединения, оба необязательны.
+// OnConnect вызывается уже после подключения к рассылке комнаты: сообщение,
+// разосланное во время загрузки истории, не теряется, но может прийти раньше
+// истории или повториться в ней.
+type Handlers struct {
+	OnConnect func(c *Client)
+	OnMessage func(c *Client, data []byte)
+}
+
+// Serve переводит запрос в WebSocket, подключает его к комнате и читает
+// входящие сообщения, пока соединение не закроется.
+func (h *Hub) Serve(w http.ResponseWriter, r *http.Request, room string, handlers Handlers) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade error: %v", err)
+		return
+	}
+
+	c := &Client{
+		hub:  h,
+		conn: conn,
+		room: room,
+		send: make(chan []byte, sendBuffer),
+		done: make(chan struct{}),
+	}
+	go c.writePump()
+
+	h.register(c)
+	if handlers.OnConnect != nil {
+		handlers.OnConnect(c)
+	}
+	c.readPump(handlers.OnMessage)
+}
+
+// Broadcast ставит сообщение в очередь всем клиентам комнаты
+func (h *Hub) Broadcast(room string, data []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for c := range h.rooms[room] {
+		c.Send(data)
+	}
+}
+
+// BroadcastJSON кодирует v один раз и рассылает его комнате
+func (h *Hub) BroadcastJSON(room string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("WebSocket encode error: %v", err)
+		return
+	}
+	h.Broadcast(room, data)
+}
+
+// Publish рассылает данные комнате на всех экземплярах сервера
+func (h *Hub) Publish(room string, data []byte) {
+	if err := h.broadcaster.Publish(room, data); err != nil {
+		log.Printf("WebSocket publish to %s failed: %v", room, err)
+	}
+}
+
+// PublishJSON кодирует v и рассылает его комнате на всех экземплярах
+func (h *Hub) PublishJSON(room string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("WebSocket encode error: %v", err)
+		return
+	}
+	h.Publish(room, data)
+}
+
+// Clients возвращает число соединений в комнате
+func (h *Hub) Clients(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Close отключает всех клиентов; новые соединения при этом не запрещаются
+func (h *Hub) Close() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, clients := range h.rooms {
+		for c := range clients {
+			c.Close()
+		}
+	}
+}
+
+func (h *Hub) register(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.rooms[c.room] == nil {
+		h.rooms[c.room] = make(map[*Client]struct{})
+	}
+	h.rooms[c.room][c] = struct{}{}
+}
+
+func (h *Hub) unregister(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if clients, ok := h.rooms[c.room]; ok {
+		delete(clients, c)
+		if len(clients) == 0 {
+			delete(h.rooms, c.room)
+		}
+	}
+}
+
+// Room возвращает комнату клиента
+func (c *Client) Room() string {
+	return c.room
+}
+
+// Send ставит сообщение в очередь, не блокируясь. Если очередь полна,
+// клиент не успевает читать и отключается.
+func (c *Client) Send(data []byte) {
+	select {
+	case <-c.done:
+	case c.send <- data:
+	default:
+		log.Printf("WebSocket client in %s is too slow, disconnecting", c.room)
+		c.Close()
+	}
+}
+
+// SendJSON кодирует v и ставит его в очередь клиента
+func (c *Client) SendJSON(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("WebSocket encode error: %v", err)
+		return
+	}
+	c.Send(data)
+}
+
+// Close завершает соединение; writePump отправит клиенту кадр закрытия
+func (c *Client) Close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+func (c *Client) readPump(onMessage func(c *Client, data []byte)) {
+	defer func() {
+		c.hub.unregister(c)
+		c.Close()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("WebSocket error: %v", err)
+			}
+			return
+		}
+		if onMessage != nil {
+			onMessage(c, data)
+		}
+	}
+}
+
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case data := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				c.Close()
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.Close()
+				return
+			}
+		case <-c.done:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+			return
+		}
+	}
+}
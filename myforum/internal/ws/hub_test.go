@@ -0,0 +1,179 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/jaxxiy/myforum/internal/broadcast"
+)
+
+func newTestServer(t *testing.T, hub *Hub, handlers Handlers) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hub.Serve(w, r, r.URL.Query().Get("room"), handlers)
+	}))
+	t.Cleanup(func() {
+		hub.Close()
+		srv.Close()
+	})
+	return srv
+}
+
+func dial(t *testing.T, srv *httptest.Server, room string) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/?room=" + room
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial %s: %v", room, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// waitClients ждет, пока хаб зарегистрирует соединения комнаты
+func waitClients(t *testing.T, hub *Hub, room string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.Clients(room) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("room %s: got %d clients, want %d", room, hub.Clients(room), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func readText(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return string(data)
+}
+
+func TestBroadcastReachesOnlyRoom(t *testing.T) {
+	hub := NewHub(broadcast.NewLocal())
+	srv := newTestServer(t, hub, Handlers{})
+
+	a := dial(t, srv, ForumRoom(1))
+	b := dial(t, srv, ForumRoom(1))
+	other := dial(t, srv, ForumRoom(2))
+	waitClients(t, hub, ForumRoom(1), 2)
+	waitClients(t, hub, ForumRoom(2), 1)
+
+	hub.BroadcastJSON(ForumRoom(1), map[string]string{"type": "message_created"})
+
+	for _, conn := range []*websocket.Conn{a, b} {
+		if got := readText(t, conn); got != `{"type":"message_created"}` {
+			t.Fatalf("got %s", got)
+		}
+	}
+
+	other.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, data, err := other.ReadMessage(); err == nil {
+		t.Fatalf("client of another room received %s", data)
+	}
+}
+
+func TestOnConnectAndOnMessage(t *testing.T) {
+	hub := NewHub(broadcast.NewLocal())
+	srv := newTestServer(t, hub, Handlers{
+		OnConnect: func(c *Client) { c.Send([]byte("history")) },
+		OnMessage: func(c *Client, data []byte) { hub.Broadcast(c.Room(), data) },
+	})
+
+	conn := dial(t, srv, GlobalChatRoom)
+	if got := readText(t, conn); got != "history" {
+		t.Fatalf("first message = %q, want history", got)
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readText(t, conn); got != "hello" {
+		t.Fatalf("echo = %q, want hello", got)
+	}
+}
+
+// Сообщение, разосланное, пока OnConnect загружает историю, доходит до клиента
+func TestBroadcastDuringOnConnect(t *testing.T) {
+	hub := NewHub(broadcast.NewLocal())
+	srv := newTestServer(t, hub, Handlers{
+		OnConnect: func(c *Client) {
+			hub.Broadcast(c.Room(), []byte("live"))
+			c.Send([]byte("history"))
+		},
+	})
+
+	conn := dial(t, srv, GlobalChatRoom)
+	got := []string{readText(t, conn), readText(t, conn)}
+	if got[0] != "live" || got[1] != "history" {
+		t.Fatalf("messages = %q, want live and history", got)
+	}
+}
+
+func TestDisconnectUnregisters(t *testing.T) {
+	hub := NewHub(broadcast.NewLocal())
+	srv := newTestServer(t, hub, Handlers{})
+
+	conn := dial(t, srv, GlobalChatRoom)
+	waitClients(t, hub, GlobalChatRoom, 1)
+
+	conn.Close()
+	waitClients(t, hub, GlobalChatRoom, 0)
+}
+
+func TestSlowClientIsEvicted(t *testing.T) {
+	c := &Client{
+		room: GlobalChatRoom,
+		send: make(chan []byte, 1),
+		done: make(chan struct{}),
+	}
+
+	c.Send([]byte("first"))
+	c.Send([]byte("second"))
+
+	select {
+	case <-c.done:
+	default:
+		t.Fatal("client with a full queue was not closed")
+	}
+	// После закрытия Send не блокируется и не паникует
+	c.Send([]byte("third"))
+}
+
+// Два хаба на общем broadcaster ведут себя как два экземпляра сервера
+func TestPublishReachesOtherHubs(t *testing.T) {
+	b := broadcast.NewLocal()
+	first, second := NewHub(b), NewHub(b)
+	srv1 := newTestServer(t, first, Handlers{})
+	srv2 := newTestServer(t, second, Handlers{})
+
+	a := dial(t, srv1, ForumRoom(1))
+	c := dial(t, srv2, ForumRoom(1))
+	waitClients(t, first, ForumRoom(1), 1)
+	waitClients(t, second, ForumRoom(1), 1)
+
+	first.PublishJSON(ForumRoom(1), map[string]string{"type": "message_created"})
+
+	for _, conn := range []*websocket.Conn{a, c} {
+		if got := readText(t, conn); got != `{"type":"message_created"}` {
+			t.Fatalf("got %s", got)
+		}
+	}
+
+	// Broadcast остается локальным
+	first.Broadcast(ForumRoom(1), []byte("local"))
+	if got := readText(t, a); got != "local" {
+		t.Fatalf("got %s", got)
+	}
+	c.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, data, err := c.ReadMessage(); err == nil {
+		t.Fatalf("other instance received local broadcast %s", data)
+	}
+}
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS messages_deleted_at_idx;
+DROP INDEX IF EXISTS forums_deleted_at_idx;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE messages DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE forums DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE forums DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,10 @@
+-- Мягкое удаление: строка остается в корзине, пока ее не вычистит cmd/purge
+ALTER TABLE forums ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE forums ADD COLUMN deleted_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE messages ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE messages ADD COLUMN deleted_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+-- Корзина и очистка выбирают только удаленные строки
+CREATE INDEX forums_deleted_at_idx ON forums(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX messages_deleted_at_idx ON messages(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,6 @@
+-- Роли и их права описаны в internal/rbac; в базе допускаются только известные роли
+UPDATE users SET role = 'user'
+WHERE role NOT IN ('admin', 'moderator', 'user', 'read-only', 'banned');
+
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('admin', 'moderator', 'user', 'read-only', 'banned'));
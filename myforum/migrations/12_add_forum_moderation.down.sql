@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS forum_moderators;
+
+ALTER TABLE forums DROP COLUMN IF EXISTS visibility;
+ALTER TABLE forums DROP COLUMN IF EXISTS owner_id;
@@ -0,0 +1,14 @@
+-- Владелец форума — его создатель; у старых форумов владельца нет
+ALTER TABLE forums ADD COLUMN owner_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+-- public — читают все, members — только вошедшие пользователи, archived — только чтение
+ALTER TABLE forums ADD COLUMN visibility VARCHAR(20) NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'members', 'archived'));
+
+-- Модераторы отдельного форума, их назначает владелец
+CREATE TABLE forum_moderators (
+    forum_id INTEGER NOT NULL REFERENCES forums(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    added_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (forum_id, user_id)
+);
@@ -0,0 +1,23 @@
+-- Refresh-токены сессий; хранится только SHA-256 от токена
+CREATE TABLE refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    -- Токен, выданный взамен при обмене; NULL — токен отозван выходом
+    replaced_by INTEGER REFERENCES refresh_tokens(id) ON DELETE SET NULL
+);
+
+CREATE INDEX refresh_tokens_user_id_idx ON refresh_tokens(user_id) WHERE revoked_at IS NULL;
+
+-- Отозванные до истечения access-токены (по claim jti); после expires_at запись не нужна
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- Выход со всех устройств: access-токены, выданные раньше, недействительны
+ALTER TABLE users ADD COLUMN tokens_revoked_at TIMESTAMP;
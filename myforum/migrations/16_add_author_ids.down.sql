@@ -0,0 +1,7 @@
+-- Имена в author возвращаются к текущим именам авторов
+UPDATE messages m SET author = u.username FROM users u WHERE u.id = m.author_id;
+UPDATE chat_messages c SET author = u.username FROM users u WHERE u.id = c.author_id;
+
+ALTER TABLE chat_messages DROP COLUMN IF EXISTS author_id;
+DROP INDEX IF EXISTS messages_author_id_idx;
+ALTER TABLE messages DROP COLUMN IF EXISTS author_id;
@@ -0,0 +1,16 @@
+-- Таблица мини-чата раньше создавалась вручную
+CREATE TABLE IF NOT EXISTS chat_messages (
+    id SERIAL PRIMARY KEY,
+    author VARCHAR(100) NOT NULL,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Автор — ссылка на пользователя. В author остается имя на момент записи:
+-- его показывают, если пользователя не нашлось или аккаунт удален
+ALTER TABLE messages ADD COLUMN author_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+UPDATE messages m SET author_id = u.id FROM users u WHERE u.username = m.author;
+CREATE INDEX messages_author_id_idx ON messages(author_id, created_at);
+
+ALTER TABLE chat_messages ADD COLUMN author_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+UPDATE chat_messages c SET author_id = u.id FROM users u WHERE u.username = c.author;
@@ -0,0 +1,13 @@
+-- Неудачные входы подряд по имени пользователя и по IP. Запись живет до
+-- expires_at: окна подсчета неудач или конца блокировки
+CREATE TABLE login_attempts (
+    scope VARCHAR(16) NOT NULL CHECK (scope IN ('username', 'ip')),
+    key VARCHAR(255) NOT NULL,
+    failures INTEGER NOT NULL,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX login_attempts_expires_at_idx ON login_attempts(expires_at);
@@ -0,0 +1,5 @@
+-- Удаление темы больше не уничтожает ее сообщения: приложение переносит их
+-- в корзину и в тему по умолчанию, а внешний ключ лишь страхует от потерь
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_topic_id_fkey;
+ALTER TABLE messages ADD CONSTRAINT messages_topic_id_fkey
+    FOREIGN KEY (topic_id) REFERENCES topics(id) ON DELETE SET NULL;
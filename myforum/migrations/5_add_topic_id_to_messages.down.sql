@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS messages_topic_id_idx;
+ALTER TABLE messages DROP COLUMN IF EXISTS topic_id;
+
+DELETE FROM topics WHERE is_default;
+DROP INDEX IF EXISTS topics_forum_default_idx;
+ALTER TABLE topics DROP COLUMN IF EXISTS is_default;
@@ -0,0 +1,18 @@
+ALTER TABLE topics ADD COLUMN is_default BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- У каждого форума не больше одной темы по умолчанию
+CREATE UNIQUE INDEX topics_forum_default_idx ON topics(forum_id) WHERE is_default;
+
+ALTER TABLE messages ADD COLUMN topic_id INTEGER REFERENCES topics(id) ON DELETE CASCADE;
+
+-- Переносим существующие сообщения в тему по умолчанию их форума
+INSERT INTO topics (forum_id, title, description, is_default)
+SELECT DISTINCT forum_id, 'Общее обсуждение', 'Сообщения, созданные до появления тем', TRUE
+FROM messages;
+
+UPDATE messages m
+SET topic_id = t.id
+FROM topics t
+WHERE t.forum_id = m.forum_id AND t.is_default;
+
+CREATE INDEX messages_topic_id_idx ON messages(topic_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS messages_parent_id_idx;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS quote_content;
+ALTER TABLE messages DROP COLUMN IF EXISTS quote_author;
+ALTER TABLE messages DROP COLUMN IF EXISTS quote_message_id;
+ALTER TABLE messages DROP COLUMN IF EXISTS parent_id;
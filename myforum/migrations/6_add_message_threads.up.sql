@@ -0,0 +1,9 @@
+-- Ответ на сообщение: при удалении родителя ответ остается корневым
+ALTER TABLE messages ADD COLUMN parent_id INTEGER REFERENCES messages(id) ON DELETE SET NULL;
+
+-- Цитата хранится снимком, без внешнего ключа: она переживает правку и удаление оригинала
+ALTER TABLE messages ADD COLUMN quote_message_id INTEGER;
+ALTER TABLE messages ADD COLUMN quote_author VARCHAR(100);
+ALTER TABLE messages ADD COLUMN quote_content TEXT;
+
+CREATE INDEX messages_parent_id_idx ON messages(parent_id);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS messages_search_vector_idx;
+DROP INDEX IF EXISTS forums_search_vector_idx;
+
+DROP TRIGGER IF EXISTS messages_search_vector_trigger ON messages;
+DROP TRIGGER IF EXISTS forums_search_vector_trigger ON forums;
+
+DROP FUNCTION IF EXISTS messages_search_vector_update();
+DROP FUNCTION IF EXISTS forums_search_vector_update();
+
+ALTER TABLE messages DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE forums DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,37 @@
+-- Конфигурация russian стеммит русские слова (russian_stem), а латиницу —
+-- английским стеммером (english_stem), так что одна колонка покрывает оба языка.
+ALTER TABLE forums ADD COLUMN search_vector tsvector;
+ALTER TABLE messages ADD COLUMN search_vector tsvector;
+
+CREATE FUNCTION forums_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('russian', coalesce(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(NEW.description, '')), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION messages_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := to_tsvector('russian', coalesce(NEW.content, ''));
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER forums_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF name, description ON forums
+    FOR EACH ROW EXECUTE FUNCTION forums_search_vector_update();
+
+CREATE TRIGGER messages_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF content ON messages
+    FOR EACH ROW EXECUTE FUNCTION messages_search_vector_update();
+
+-- Заполняем векторы для уже существующих строк
+UPDATE forums SET search_vector =
+    setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('russian', coalesce(description, '')), 'B');
+UPDATE messages SET search_vector = to_tsvector('russian', coalesce(content, ''));
+
+CREATE INDEX forums_search_vector_idx ON forums USING GIN (search_vector);
+CREATE INDEX messages_search_vector_idx ON messages USING GIN (search_vector);
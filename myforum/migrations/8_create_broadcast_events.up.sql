@@ -0,0 +1,9 @@
+-- События WebSocket длиннее лимита NOTIFY (8000 байт): в уведомлении идет только id
+CREATE TABLE broadcast_events (
+    id BIGSERIAL PRIMARY KEY,
+    room VARCHAR(100) NOT NULL,
+    data TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX broadcast_events_created_at_idx ON broadcast_events(created_at);
@@ -0,0 +1,13 @@
+-- Время последней правки; NULL — сообщение не редактировалось
+ALTER TABLE messages ADD COLUMN edited_at TIMESTAMP;
+
+-- Прежние версии сообщения: текст до правки, кто и когда его заменил
+CREATE TABLE message_revisions (
+    id SERIAL PRIMARY KEY,
+    message_id INTEGER NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    editor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    edited_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX message_revisions_message_id_idx ON message_revisions(message_id, edited_at);
@@ -0,0 +1,129 @@
+package jwt
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultJWKSRefresh — как долго RemoteKeys доверяет загруженному набору ключей
+const DefaultJWKSRefresh = 5 * time.Minute
+
+// Неизвестный kid заставляет перечитать JWKS раньше срока, но не чаще этого:
+// иначе поток токенов с выдуманными kid превратится в поток запросов к сервису авторизации.
+const minJWKSRefetch = 10 * time.Second
+
+// RemoteKeys — ключи из JWKS сервиса авторизации. Набор кешируется на refresh
+// и перечитывается раньше, если пришел токен с новым kid (ключ сменили).
+// Загрузка идет без блокировки: пока она не закончилась, известные ключи
+// отдаются из кеша, а одновременные запросы нового kid ждут одну загрузку.
+type RemoteKeys struct {
+	url     string
+	refresh time.Duration
+	client  *http.Client
+
+	mu         sync.Mutex
+	keys       StaticKeys
+	fetched    time.Time     // последняя удачная загрузка
+	attempted  time.Time     // последняя попытка загрузки
+	fetchErr   error         // ошибка последней попытки
+	refreshing chan struct{} // закрывается по окончании текущей загрузки
+}
+
+func NewRemoteKeys(url string, refresh time.Duration) *RemoteKeys {
+	if refresh <= 0 {
+		refresh = DefaultJWKSRefresh
+	}
+	return &RemoteKeys{
+		url:     url,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (r *RemoteKeys) Key(kid string) (crypto.PublicKey, error) {
+	r.mu.Lock()
+	key, err := r.keys.Key(kid)
+	if err == nil {
+		// Устаревший набор обновляется в фоне, известный ключ остается в силе
+		if time.Since(r.fetched) >= r.refresh {
+			r.startRefresh()
+		}
+		r.mu.Unlock()
+		return key, nil
+	}
+	done := r.startRefresh()
+	r.mu.Unlock()
+	if done == nil {
+		return nil, err
+	}
+
+	// Новый kid: ждем загрузку, свою или уже идущую
+	<-done
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, err = r.keys.Key(kid)
+	if err != nil && r.fetchErr != nil {
+		return nil, r.fetchErr
+	}
+	return key, err
+}
+
+// startRefresh запускает загрузку JWKS, если она еще не идет и не запрещена
+// minJWKSRefetch, и возвращает канал ее окончания; nil — загрузки не будет.
+// Вызывается под r.mu.
+func (r *RemoteKeys) startRefresh() <-chan struct{} {
+	if r.refreshing != nil {
+		return r.refreshing
+	}
+	if time.Since(r.attempted) < minJWKSRefetch {
+		return nil
+	}
+	r.attempted = time.Now()
+	done := make(chan struct{})
+	r.refreshing = done
+
+	go func() {
+		keys, err := r.fetch()
+
+		r.mu.Lock()
+		if err == nil {
+			r.keys = keys
+			r.fetched = time.Now()
+		}
+		r.fetchErr = err
+		r.refreshing = nil
+		r.mu.Unlock()
+		close(done)
+	}()
+	return done
+}
+
+// fetch загружает JWKS; r.mu при этом не держится
+func (r *RemoteKeys) fetch() (StaticKeys, error) {
+	resp, err := r.client.Get(r.url)
+	if err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks: %s", resp.Status)
+	}
+
+	var set JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+	keys := make(StaticKeys, 0, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue // ключи неизвестных типов пропускаем
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
@@ -1,35 +1,221 @@
+// Package jwt выпускает и проверяет access-токены форума. Им пользуются
+// сервис авторизации, обработчики, middleware и gRPC-сервис форума.
 package jwt
 
 import (
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Издатель и получатель токенов, если они не заданы в конфигурации
+const (
+	DefaultIssuer   = "myforum-auth"
+	DefaultAudience = "myforum"
+)
+
+// ErrTokenRevoked — подпись и срок в порядке, но токен отозван
+var ErrTokenRevoked = errors.New("token revoked")
+
+// Claims — содержимое access-токена. Издатель (iss), получатель (aud),
+// ID токена (jti), время выдачи и истечения лежат в RegisteredClaims.
 type Claims struct {
-	UserID int `json:"user_id"`
+	UserID   int    `json:"user_id"`
+	Username string `json:"username,omitempty"`
+	Role     string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID int, secret string, expiresIn time.Duration) (string, error) {
-	claims := &Claims{
-		UserID: userID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
-		},
+// Config — ключи и кто выпускает токены и для кого. Асимметричные ключи
+// (SigningKeyFile, VerificationKeyFiles, JWKSURL) заменяют общий секрет Key.
+type Config struct {
+	Key string // секрет HS256
+	// PEM с закрытым ключом RSA (RS256) или Ed25519 (EdDSA); без него Manager
+	// только проверяет токены
+	SigningKeyFile string
+	// PEM с открытыми ключами, которые еще принимаются, например прежний ключ при смене
+	VerificationKeyFiles []string
+	// JWKS сервиса авторизации и как долго кешировать его
+	JWKSURL     string
+	JWKSRefresh time.Duration
+
+	Issuer   string
+	Audience string
+}
+
+// ErrNoSigningKey — Manager настроен только на проверку токенов
+var ErrNoSigningKey = errors.New("no signing key configured")
+
+// Manager подписывает и проверяет токены
+type Manager struct {
+	hmacKey []byte      // общий секрет, если асимметричные ключи не заданы
+	signer  *PrivateKey // nil — подписывать нечем
+	keys    KeySet      // ключи проверки по kid
+	static  StaticKeys  // свои ключи, они же отдаются в JWKS
+
+	issuer   string
+	audience string
+	parser   *jwt.Parser
+}
+
+func NewManager(cfg Config) (*Manager, error) {
+	if cfg.Issuer == "" {
+		cfg.Issuer = DefaultIssuer
+	}
+	if cfg.Audience == "" {
+		cfg.Audience = DefaultAudience
+	}
+	m := &Manager{issuer: cfg.Issuer, audience: cfg.Audience}
+
+	if cfg.SigningKeyFile != "" {
+		signer, err := LoadPrivateKey(cfg.SigningKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("signing key: %w", err)
+		}
+		m.signer = signer
+		m.static = append(m.static, signer.Public())
+	}
+	for _, path := range cfg.VerificationKeyFiles {
+		key, err := LoadPublicKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("verification key: %w", err)
+		}
+		m.static = append(m.static, key)
+	}
+
+	switch {
+	case cfg.JWKSURL != "":
+		m.keys = keyChain{m.static, NewRemoteKeys(cfg.JWKSURL, cfg.JWKSRefresh)}
+	case len(m.static) > 0:
+		m.keys = m.static
+	case cfg.Key != "":
+		m.hmacKey = []byte(cfg.Key)
+	default:
+		return nil, errors.New("no key configured")
+	}
+
+	// Алгоритм определяется ключами, а не заголовком токена: с открытыми
+	// ключами HS256 не принимается, иначе открытый ключ стал бы секретом
+	methods := []string{jwt.SigningMethodHS256.Alg()}
+	if m.keys != nil {
+		methods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+	}
+	m.parser = jwt.NewParser(
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithAudience(cfg.Audience),
+		jwt.WithExpirationRequired(),
+	)
+	return m, nil
+}
+
+// Issue подписывает токен пользователя из claims на срок ttl. Издатель,
+// получатель, ID токена и время выдачи и истечения заполняет Manager.
+func (m *Manager) Issue(claims Claims, ttl time.Duration) (string, error) {
+	if m.signer == nil && m.hmacKey == nil {
+		return "", ErrNoSigningKey
+	}
+	id, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    m.issuer,
+		Audience:  jwt.ClaimStrings{m.audience},
+		ID:        id,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+
+	if m.signer == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, &claims).SignedString(m.hmacKey)
+	}
+	token := jwt.NewWithClaims(m.signer.method, &claims)
+	token.Header["kid"] = m.signer.public.ID
+	return token.SignedString(m.signer.key)
+}
+
+// Parse проверяет подпись, алгоритм, срок, издателя и получателя токена
+func (m *Manager) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := m.parser.ParseWithClaims(tokenString, claims, m.verificationKey)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || claims.UserID == 0 {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
+
+func (m *Manager) verificationKey(token *jwt.Token) (interface{}, error) {
+	if m.keys == nil {
+		return m.hmacKey, nil
 	}
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, ErrUnknownKey
+	}
+	return m.keys.Key(kid)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+// JWKS — открытые ключи Manager для /.well-known/jwks.json. При общем
+// секрете набор пуст.
+func (m *Manager) JWKS() JWKS {
+	return m.static.JWKS()
 }
 
-func ParseToken(tokenString, secret string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+// keyChain ищет ключ в наборах по очереди
+type keyChain []KeySet
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+func (c keyChain) Key(kid string) (crypto.PublicKey, error) {
+	var err error
+	for _, keys := range c {
+		var key crypto.PublicKey
+		if key, err = keys.Key(kid); err == nil {
+			return key, nil
+		}
 	}
 	return nil, err
 }
+
+// RevocationChecker — список отозванных токенов (repository.RevocationChecker)
+type RevocationChecker interface {
+	IsAccessTokenRevoked(jti string, userID int, issuedAt time.Time) (bool, error)
+}
+
+// ParseActive — как Parse, но еще отклоняет отозванные токены
+func (m *Manager) ParseActive(tokenString string, revocations RevocationChecker) (*Claims, error) {
+	claims, err := m.Parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	// У токена без iat время выдачи считается нулевым: его отзывает любой выход со всех устройств
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	revoked, err := revocations.IsAccessTokenRevoked(claims.ID, claims.UserID, issuedAt)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,268 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type revokedIDs map[string]bool
+
+func (r revokedIDs) IsAccessTokenRevoked(jti string, userID int, issuedAt time.Time) (bool, error) {
+	return r[jti], nil
+}
+
+func TestIssueAndParse(t *testing.T) {
+	m, _ := NewManager(Config{Key: "secret"})
+	token, err := m.Issue(Claims{UserID: 7, Username: "alice", Role: "moderator"}, time.Hour)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+
+	claims, err := m.Parse(token)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if claims.UserID != 7 || claims.Username != "alice" || claims.Role != "moderator" {
+		t.Errorf("user claims = %+v", claims)
+	}
+	if claims.Issuer != DefaultIssuer || len(claims.Audience) != 1 || claims.Audience[0] != DefaultAudience {
+		t.Errorf("iss/aud = %q/%v", claims.Issuer, claims.Audience)
+	}
+	if claims.ID == "" || claims.IssuedAt == nil {
+		t.Errorf("token has no jti or iat: %+v", claims.RegisteredClaims)
+	}
+
+	other, _ := m.Issue(Claims{UserID: 7}, time.Hour)
+	if again, _ := m.Parse(other); again.ID == claims.ID {
+		t.Error("two tokens share a jti")
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	m, _ := NewManager(Config{Key: "secret"})
+	issue := func(cfg Config, ttl time.Duration) string {
+		m, err := NewManager(cfg)
+		if err != nil {
+			t.Fatalf("new manager: %v", err)
+		}
+		token, err := m.Issue(Claims{UserID: 1}, ttl)
+		if err != nil {
+			t.Fatalf("issue: %v", err)
+		}
+		return token
+	}
+	hs512, _ := jwt.NewWithClaims(jwt.SigningMethodHS512, &Claims{
+		UserID: 1,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    DefaultIssuer,
+			Audience:  jwt.ClaimStrings{DefaultAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte("secret"))
+
+	tests := map[string]string{
+		"other key":      issue(Config{Key: "other"}, time.Hour),
+		"other issuer":   issue(Config{Key: "secret", Issuer: "elsewhere"}, time.Hour),
+		"other audience": issue(Config{Key: "secret", Audience: "billing"}, time.Hour),
+		"expired":        issue(Config{Key: "secret"}, -time.Minute),
+		"other alg":      hs512,
+		"garbage":        "not-a-token",
+	}
+	for name, token := range tests {
+		if _, err := m.Parse(token); err == nil {
+			t.Errorf("%s: token accepted", name)
+		}
+	}
+}
+
+func TestParseActive(t *testing.T) {
+	m, _ := NewManager(Config{Key: "secret"})
+	token, _ := m.Issue(Claims{UserID: 1}, time.Hour)
+	claims, _ := m.Parse(token)
+
+	if _, err := m.ParseActive(token, revokedIDs{}); err != nil {
+		t.Fatalf("active token rejected: %v", err)
+	}
+	if _, err := m.ParseActive(token, revokedIDs{claims.ID: true}); !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("revoked token: got %v, want ErrTokenRevoked", err)
+	}
+}
+
+// writeKey сохраняет закрытый ключ в PEM (PKCS#8) во временный каталог
+func writeKey(t *testing.T, key crypto.Signer) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newManager(t *testing.T, cfg Config) *Manager {
+	t.Helper()
+	m, err := NewManager(cfg)
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	return m
+}
+
+func TestAsymmetricKeys(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	for name, key := range map[string]crypto.Signer{"RS256": rsaKey, "EdDSA": edKey} {
+		m := newManager(t, Config{SigningKeyFile: writeKey(t, key)})
+		token, err := m.Issue(Claims{UserID: 3}, time.Hour)
+		if err != nil {
+			t.Fatalf("%s: issue: %v", name, err)
+		}
+		parsed, _, _ := jwt.NewParser().ParseUnverified(token, &Claims{})
+		if parsed.Method.Alg() != name || parsed.Header["kid"] != m.JWKS().Keys[0].Kid {
+			t.Errorf("%s: header = %v", name, parsed.Header)
+		}
+		if claims, err := m.Parse(token); err != nil || claims.UserID != 3 {
+			t.Errorf("%s: parse: %v", name, err)
+		}
+	}
+
+	// HS256, подписанный хоть каким секретом, не принимается, если настроены ключи
+	m := newManager(t, Config{SigningKeyFile: writeKey(t, edKey), Key: "secret"})
+	hs, _ := newManager(t, Config{Key: "secret"}).Issue(Claims{UserID: 3}, time.Hour)
+	if _, err := m.Parse(hs); err == nil {
+		t.Error("HS256 token accepted by asymmetric manager")
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	_, oldKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+	oldPath, newPath := writeKey(t, oldKey), writeKey(t, newKey)
+
+	before := newManager(t, Config{SigningKeyFile: oldPath})
+	oldToken, _ := before.Issue(Claims{UserID: 1}, time.Hour)
+
+	after := newManager(t, Config{SigningKeyFile: newPath, VerificationKeyFiles: []string{oldPath}})
+	newToken, _ := after.Issue(Claims{UserID: 1}, time.Hour)
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, err := after.Parse(token); err != nil {
+			t.Errorf("%s key: %v", name, err)
+		}
+	}
+	if len(after.JWKS().Keys) != 2 {
+		t.Errorf("jwks has %d keys, want 2", len(after.JWKS().Keys))
+	}
+
+	// Без прежнего ключа старые токены отклоняются
+	if _, err := newManager(t, Config{SigningKeyFile: newPath}).Parse(oldToken); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("retired key: got %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	auth := newManager(t, Config{SigningKeyFile: writeKey(t, rsaKey)})
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		json.NewEncoder(w).Encode(auth.JWKS())
+	}))
+	defer srv.Close()
+
+	forum := newManager(t, Config{JWKSURL: srv.URL})
+	if _, err := forum.Issue(Claims{UserID: 1}, time.Hour); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("verify-only manager issued a token: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		token, _ := auth.Issue(Claims{UserID: 5}, time.Hour)
+		if claims, err := forum.Parse(token); err != nil || claims.UserID != 5 {
+			t.Fatalf("parse via jwks: %v", err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("jwks fetched %d times, want 1", n)
+	}
+
+	// Чужой kid не приводит к повторной загрузке чаще minJWKSRefetch
+	_, stranger, _ := ed25519.GenerateKey(rand.Reader)
+	token, _ := newManager(t, Config{SigningKeyFile: writeKey(t, stranger)}).Issue(Claims{UserID: 5}, time.Hour)
+	for i := 0; i < 3; i++ {
+		if _, err := forum.Parse(token); err == nil {
+			t.Fatal("token signed by unknown key accepted")
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("jwks fetched %d times after unknown kid, want 1", n)
+	}
+}
+
+func TestJWKSRefreshDoesNotBlock(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	auth := newManager(t, Config{SigningKeyFile: writeKey(t, rsaKey)})
+
+	release := make(chan struct{})
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) > 1 {
+			<-release // повторная загрузка зависла
+		}
+		json.NewEncoder(w).Encode(auth.JWKS())
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	forum := newManager(t, Config{JWKSURL: srv.URL, JWKSRefresh: time.Millisecond})
+	token, _ := auth.Issue(Claims{UserID: 5}, time.Hour)
+	if _, err := forum.Parse(token); err != nil {
+		t.Fatalf("parse via jwks: %v", err)
+	}
+
+	// Набор устарел, загрузка висит — известный ключ отдается из кеша
+	remote := forum.keys.(keyChain)[1].(*RemoteKeys)
+	remote.mu.Lock()
+	remote.attempted = time.Time{}
+	remote.mu.Unlock()
+	time.Sleep(2 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < 3; i++ {
+			if _, err := forum.Parse(token); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("parse during refresh: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("parse blocked by jwks refresh")
+	}
+	if n := requests.Load(); n > 2 {
+		t.Errorf("jwks fetched %d times, want at most one refresh in flight", n)
+	}
+}
@@ -0,0 +1,263 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrUnknownKey — в наборе нет ключа с kid из заголовка токена
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// PublicKey — ключ проверки подписи. ID (kid) — отпечаток ключа по RFC 7638,
+// поэтому у одного ключа он одинаковый в сервисе авторизации и в JWKS.
+type PublicKey struct {
+	ID  string
+	Key crypto.PublicKey // *rsa.PublicKey или ed25519.PublicKey
+}
+
+// PrivateKey — ключ подписи RS256 или EdDSA
+type PrivateKey struct {
+	public PublicKey
+	method jwt.SigningMethod
+	key    crypto.Signer
+}
+
+// Public возвращает открытую часть ключа с тем же kid
+func (k *PrivateKey) Public() PublicKey {
+	return k.public
+}
+
+// LoadPrivateKey читает закрытый ключ RSA или Ed25519 из PEM-файла
+// (PKCS#8 или PKCS#1)
+func LoadPrivateKey(path string) (*PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parsePrivateKey(block)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return newPrivateKey(key)
+}
+
+func newPrivateKey(key crypto.Signer) (*PrivateKey, error) {
+	var method jwt.SigningMethod
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	public, err := newPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{public: public, method: method, key: key}, nil
+}
+
+// LoadPublicKey читает открытый ключ RSA или Ed25519 из PEM-файла. Подходит
+// и файл с закрытым ключом: из него берется открытая часть.
+func LoadPublicKey(path string) (PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return PublicKey{}, err
+	}
+
+	var key crypto.PublicKey
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		var private crypto.Signer
+		if private, err = parsePrivateKey(block); err == nil {
+			key = private.Public()
+		}
+	}
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return newPublicKey(key)
+}
+
+func newPublicKey(key crypto.PublicKey) (PublicKey, error) {
+	jwk, err := publicJWK(key)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	return PublicKey{ID: jwk.thumbprint(), Key: key}, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data", path)
+	}
+	return block, nil
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
+
+// KeySet ищет ключ проверки подписи по kid
+type KeySet interface {
+	Key(kid string) (crypto.PublicKey, error)
+}
+
+// StaticKeys — ключи, загруженные при старте
+type StaticKeys []PublicKey
+
+func (s StaticKeys) Key(kid string) (crypto.PublicKey, error) {
+	for _, k := range s {
+		if k.ID == kid {
+			return k.Key, nil
+		}
+	}
+	return nil, ErrUnknownKey
+}
+
+// JWKS возвращает ключи в формате JSON Web Key Set (RFC 7517)
+func (s StaticKeys) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(s))}
+	for _, k := range s {
+		jwk, err := publicJWK(k.Key)
+		if err != nil {
+			continue // в StaticKeys попадают только поддерживаемые ключи
+		}
+		jwk.Kid = k.ID
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// JWKS — набор открытых ключей, который отдает /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK — открытый ключ RSA (kty RSA) или Ed25519 (kty OKP)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+func publicJWK(key crypto.PublicKey) (JWK, error) {
+	enc := base64.RawURLEncoding
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			N:   enc.EncodeToString(k.N.Bytes()),
+			E:   enc.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Use: "sig",
+			Alg: jwt.SigningMethodEdDSA.Alg(),
+			Crv: "Ed25519",
+			X:   enc.EncodeToString(k),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// PublicKey восстанавливает ключ из JWK
+func (j JWK) PublicKey() (PublicKey, error) {
+	enc := base64.RawURLEncoding
+	var key crypto.PublicKey
+	switch {
+	case j.Kty == "RSA":
+		n, err := enc.DecodeString(j.N)
+		if err != nil {
+			return PublicKey{}, fmt.Errorf("jwk %s: n: %w", j.Kid, err)
+		}
+		e, err := enc.DecodeString(j.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return PublicKey{}, fmt.Errorf("jwk %s: invalid e", j.Kid)
+		}
+		key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	case j.Kty == "OKP" && j.Crv == "Ed25519":
+		x, err := enc.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return PublicKey{}, fmt.Errorf("jwk %s: invalid x", j.Kid)
+		}
+		key = ed25519.PublicKey(x)
+	default:
+		return PublicKey{}, fmt.Errorf("jwk %s: unsupported key type %s", j.Kid, j.Kty)
+	}
+
+	kid := j.Kid
+	if kid == "" {
+		kid = j.thumbprint()
+	}
+	return PublicKey{ID: kid, Key: key}, nil
+}
+
+// thumbprint — отпечаток ключа по RFC 7638: SHA-256 от обязательных полей
+// в лексикографическом порядке
+func (j JWK) thumbprint() string {
+	var required interface{}
+	switch j.Kty {
+	case "RSA":
+		required = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{j.E, j.Kty, j.N}
+	default:
+		required = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{j.Crv, j.Kty, j.X}
+	}
+	data, _ := json.Marshal(required)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}